@@ -5,6 +5,7 @@ import (
 	"os"
 	"testing"
 
+	"github.com/OpenVPN/terraform-provider-openvpn-cloud/internal/fakeapi"
 	"github.com/openvpn/cloudconnexa-go-client/v2/cloudconnexa"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -32,10 +33,19 @@ func TestNewClient(t *testing.T) {
 	assert.NotEmpty(t, c.Token)
 }
 
+// setUpClient connects to the tenant given by the environment or, when none is
+// configured, to an in-process fake of the Cloud Connexa API.
 func setUpClient(t *testing.T) *cloudconnexa.Client {
-	validateEnvVars(t)
+	host, clientId, clientSecret := os.Getenv(HostEnvVar), os.Getenv(ClientIDEnvVar), os.Getenv(ClientSecretEnvVar)
+	if host == "" && clientId == "" && clientSecret == "" {
+		server := fakeapi.NewServer()
+		t.Cleanup(server.Close)
+		host, clientId, clientSecret = server.URL, server.ClientID, server.ClientSecret
+	} else {
+		validateEnvVars(t)
+	}
 	var err error
-	client, err := cloudconnexa.NewClient(os.Getenv(HostEnvVar), os.Getenv(ClientIDEnvVar), os.Getenv(ClientSecretEnvVar))
+	client, err := cloudconnexa.NewClient(host, clientId, clientSecret)
 	require.NoError(t, err)
 	return client
 }
@@ -0,0 +1,107 @@
+package cloudconnexa
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// newBaseTransport returns the transport that sends the requests of the provider,
+// including the token request, configured with the proxy and TLS settings.
+func newBaseTransport(d *schema.ResourceData) (*http.Transport, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if proxyUrl := d.Get("proxy_url").(string); proxyUrl != "" {
+		u, err := url.Parse(proxyUrl)
+		if err != nil {
+			return nil, append(diags, diag.Errorf("Invalid proxy_url: %v", err)...)
+		}
+		transport.Proxy = http.ProxyURL(u)
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	caFile, caPem := d.Get("ca_cert_file").(string), d.Get("ca_cert_pem").(string)
+	if caFile != "" || caPem != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if caFile != "" {
+			pem, err := os.ReadFile(caFile)
+			if err != nil {
+				return nil, append(diags, diag.Errorf("Unable to read ca_cert_file: %v", err)...)
+			}
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, append(diags, diag.Errorf("ca_cert_file %s does not contain any PEM encoded certificate", caFile)...)
+			}
+		}
+		if caPem != "" && !pool.AppendCertsFromPEM([]byte(caPem)) {
+			return nil, append(diags, diag.Errorf("ca_cert_pem does not contain any PEM encoded certificate")...)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	cert, err := clientCertificate(d)
+	if err != nil {
+		return nil, append(diags, diag.Errorf("Unable to load the client certificate: %v", err)...)
+	}
+	if cert != nil {
+		tlsConfig.Certificates = []tls.Certificate{*cert}
+	}
+
+	if d.Get("insecure_skip_verify").(bool) {
+		tlsConfig.InsecureSkipVerify = true
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  "TLS certificate verification is disabled",
+			Detail: "insecure_skip_verify is set, so the provider accepts any certificate presented by the Cloud Connexa API " +
+				"or a proxy. Anyone able to intercept the traffic can read the API credentials and tokens. " +
+				"Use ca_cert_file or ca_cert_pem to trust a private CA instead.",
+		})
+	}
+	transport.TLSClientConfig = tlsConfig
+	return transport, diags
+}
+
+// clientCertificate returns the certificate used for mutual TLS, or nil if none is
+// configured.
+func clientCertificate(d *schema.ResourceData) (*tls.Certificate, error) {
+	certFile, keyFile := d.Get("client_cert_file").(string), d.Get("client_key_file").(string)
+	certPem, keyPem := d.Get("client_cert_pem").(string), d.Get("client_key_pem").(string)
+	switch {
+	case certFile != "" && keyFile != "":
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		return &cert, err
+	case certPem != "" && keyPem != "":
+		cert, err := tls.X509KeyPair([]byte(certPem), []byte(keyPem))
+		return &cert, err
+	case certFile != "" || keyFile != "" || certPem != "" || keyPem != "":
+		return nil, errors.New("both the certificate and its private key must be set")
+	}
+	return nil, nil
+}
+
+// validateProxyUrl accepts the proxy schemes supported by net/http.
+func validateProxyUrl(i interface{}, k string) ([]string, []error) {
+	u, err := url.Parse(i.(string))
+	if err != nil {
+		return nil, []error{fmt.Errorf("%s is not a valid URL: %v", k, err)}
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, []error{fmt.Errorf("%s must use the http, https or socks5 scheme, got %q", k, u.Scheme)}
+	}
+	if u.Host == "" {
+		return nil, []error{fmt.Errorf("%s must include a host", k)}
+	}
+	return nil, nil
+}
@@ -0,0 +1,176 @@
+package cloudconnexa
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/OpenVPN/terraform-provider-openvpn-cloud/internal/fakeapi"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestBaseTransport(t *testing.T, raw map[string]interface{}) (*http.Transport, diag.Diagnostics) {
+	return newBaseTransport(schema.TestResourceDataRaw(t, Provider().Schema, raw))
+}
+
+func certificatePEM(cert *x509.Certificate) string {
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}))
+}
+
+// newClientCertificate returns a self-signed client certificate and its key, PEM encoded.
+func newClientCertificate(t *testing.T) (*x509.Certificate, string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "terraform"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	return cert, certificatePEM(cert), string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}))
+}
+
+func TestNewBaseTransport_proxy(t *testing.T) {
+	api := fakeapi.NewServer()
+	defer api.Close()
+	var proxied int32
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !r.URL.IsAbs() {
+			http.Error(w, "not a proxy request", http.StatusBadRequest)
+			return
+		}
+		atomic.AddInt32(&proxied, 1)
+		r.RequestURI = ""
+		resp, err := http.DefaultTransport.RoundTrip(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		defer resp.Body.Close()
+		for name, values := range resp.Header {
+			w.Header()[name] = values
+		}
+		w.WriteHeader(resp.StatusCode)
+		_, _ = io.Copy(w, resp.Body)
+	}))
+	defer proxy.Close()
+
+	transport, diags := newTestBaseTransport(t, map[string]interface{}{"proxy_url": proxy.URL})
+	require.False(t, diags.HasError(), "%v", diags)
+	c, err := newClient(context.Background(), api.URL, api.ClientID, api.ClientSecret, transport)
+	require.NoError(t, err)
+	_, err = c.client.Networks.List()
+	require.NoError(t, err)
+
+	assert.EqualValues(t, 2, atomic.LoadInt32(&proxied), "the token request and the API call must both use the proxy")
+}
+
+func TestNewBaseTransport_caCert(t *testing.T) {
+	api := fakeapi.NewUnstartedServer()
+	api.StartTLS()
+	defer api.Close()
+	caPem := certificatePEM(api.Certificate())
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(caFile, []byte(caPem), 0o600))
+
+	transport, diags := newTestBaseTransport(t, map[string]interface{}{})
+	require.False(t, diags.HasError())
+	_, err := newClient(context.Background(), api.URL, api.ClientID, api.ClientSecret, transport)
+	assert.ErrorContains(t, err, "certificate", "the private CA must not be trusted by default")
+
+	for _, raw := range []map[string]interface{}{{"ca_cert_pem": caPem}, {"ca_cert_file": caFile}} {
+		transport, diags := newTestBaseTransport(t, raw)
+		require.False(t, diags.HasError(), "%v", diags)
+		_, err := newClient(context.Background(), api.URL, api.ClientID, api.ClientSecret, transport)
+		assert.NoError(t, err, "%v", raw)
+	}
+
+	_, diags = newTestBaseTransport(t, map[string]interface{}{"ca_cert_pem": "not a certificate"})
+	assert.True(t, diags.HasError())
+}
+
+func TestNewBaseTransport_insecureSkipVerify(t *testing.T) {
+	api := fakeapi.NewUnstartedServer()
+	api.StartTLS()
+	defer api.Close()
+
+	transport, diags := newTestBaseTransport(t, map[string]interface{}{"insecure_skip_verify": true})
+	require.False(t, diags.HasError())
+	require.Len(t, diags, 1)
+	assert.Equal(t, diag.Warning, diags[0].Severity)
+	assert.Equal(t, "TLS certificate verification is disabled", diags[0].Summary)
+
+	_, err := newClient(context.Background(), api.URL, api.ClientID, api.ClientSecret, transport)
+	assert.NoError(t, err)
+}
+
+func TestNewBaseTransport_clientCertificate(t *testing.T) {
+	clientCert, certPem, keyPem := newClientCertificate(t)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert)
+	api := fakeapi.NewUnstartedServer()
+	api.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	api.StartTLS()
+	defer api.Close()
+	caPem := certificatePEM(api.Certificate())
+
+	transport, diags := newTestBaseTransport(t, map[string]interface{}{"ca_cert_pem": caPem})
+	require.False(t, diags.HasError())
+	_, err := newClient(context.Background(), api.URL, api.ClientID, api.ClientSecret, transport)
+	assert.Error(t, err, "the API must reject connections without a client certificate")
+
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "client.pem"), filepath.Join(dir, "client.key")
+	require.NoError(t, os.WriteFile(certFile, []byte(certPem), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, []byte(keyPem), 0o600))
+	for _, raw := range []map[string]interface{}{
+		{"ca_cert_pem": caPem, "client_cert_pem": certPem, "client_key_pem": keyPem},
+		{"ca_cert_pem": caPem, "client_cert_file": certFile, "client_key_file": keyFile},
+	} {
+		transport, diags := newTestBaseTransport(t, raw)
+		require.False(t, diags.HasError(), "%v", diags)
+		_, err := newClient(context.Background(), api.URL, api.ClientID, api.ClientSecret, transport)
+		assert.NoError(t, err)
+	}
+
+	_, diags = newTestBaseTransport(t, map[string]interface{}{"client_cert_pem": certPem})
+	assert.True(t, diags.HasError(), "a certificate without its key must be rejected")
+}
+
+func TestValidateProxyUrl(t *testing.T) {
+	for _, valid := range []string{"http://proxy:3128", "https://proxy.example.com", "socks5://127.0.0.1:1080"} {
+		_, errs := validateProxyUrl(valid, "proxy_url")
+		assert.Empty(t, errs, valid)
+	}
+	for _, invalid := range []string{"proxy:3128", "ftp://proxy", "http://", "://"} {
+		_, errs := validateProxyUrl(invalid, "proxy_url")
+		assert.NotEmpty(t, errs, invalid)
+	}
+}
@@ -0,0 +1,363 @@
+package cloudconnexa
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/OpenVPN/terraform-provider-openvpn-cloud/internal/fakeapi"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/openvpn/cloudconnexa-go-client/v2/cloudconnexa"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// CassetteModeEnvVar selects whether the acceptance tests record the API traffic to
+// testdata/cassettes (`record`) or replay it from there without credentials (`replay`).
+const CassetteModeEnvVar = "CLOUDCONNEXA_CASSETTE_MODE"
+
+const (
+	cassetteModeRecord = "record"
+	cassetteModeReplay = "replay"
+	cassetteDir        = "testdata/cassettes"
+)
+
+var testAccCassetteMode = os.Getenv(CassetteModeEnvVar)
+
+// testAccTransport is the base transport of the provider used by the acceptance tests.
+// It passes requests through unless a test has loaded a cassette.
+var testAccTransport = &cassetteTransport{next: http.DefaultTransport}
+
+// testAccUseCassette records or replays the API traffic of the test, depending on
+// CLOUDCONNEXA_CASSETTE_MODE. The test must generate its random names with the returned
+// source, which is seeded from the name of the test when a cassette is used so that the
+// names are the same when the cassette is replayed.
+func testAccUseCassette(t *testing.T) testAccRandom {
+	if testAccCassetteMode == "" || os.Getenv(resource.EnvTfAcc) == "" {
+		return testAccRandom{rand.New(rand.NewSource(time.Now().UnixNano()))}
+	}
+	h := fnv.New64a()
+	h.Write([]byte(t.Name()))
+	random := testAccRandom{rand.New(rand.NewSource(int64(h.Sum64())))}
+
+	path := filepath.Join(cassetteDir, strings.ReplaceAll(t.Name(), "/", "_")+".json")
+	switch testAccCassetteMode {
+	case cassetteModeRecord:
+		testAccTransport.record()
+		t.Cleanup(func() {
+			c := testAccTransport.stop()
+			if t.Failed() || t.Skipped() {
+				// Keep the previous recording rather than replace it with an incomplete one.
+				return
+			}
+			if err := c.save(path); err != nil {
+				t.Errorf("unable to save cassette: %v", err)
+			}
+		})
+	case cassetteModeReplay:
+		c, err := loadCassette(path)
+		if errors.Is(err, os.ErrNotExist) {
+			// A missing cassette must not pass for a replayed test.
+			t.Fatalf("no cassette has been recorded at %s: record it with %s=%s against a tenant or the fake API", path, CassetteModeEnvVar, cassetteModeRecord)
+		}
+		if err != nil {
+			t.Fatalf("unable to load cassette: %v", err)
+		}
+		testAccTransport.replay(c)
+		t.Cleanup(func() { testAccTransport.stop() })
+	default:
+		t.Fatalf("%s must be %q or %q, got %q", CassetteModeEnvVar, cassetteModeRecord, cassetteModeReplay, testAccCassetteMode)
+	}
+	return random
+}
+
+// testAccRandom generates the random values of an acceptance test the way the acctest
+// functions of the same names do, from its own source rather than the global one.
+type testAccRandom struct {
+	*rand.Rand
+}
+
+func (r testAccRandom) RandomWithPrefix(prefix string) string {
+	return fmt.Sprintf("%s-%d", prefix, r.Int())
+}
+
+func (r testAccRandom) RandString(n int) string {
+	return r.RandStringFromCharSet(n, acctest.CharSetAlphaNum)
+}
+
+func (r testAccRandom) RandStringFromCharSet(n int, charSet string) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = charSet[r.Intn(len(charSet))]
+	}
+	return string(b)
+}
+
+// RandIpAddress returns a random address of the given subnet.
+func (r testAccRandom) RandIpAddress(cidr string) (string, error) {
+	_, subnet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return "", err
+	}
+	ip := make(net.IP, len(subnet.IP))
+	for i := range ip {
+		ip[i] = subnet.IP[i] | (^subnet.Mask[i] & byte(r.Intn(256)))
+	}
+	return ip.String(), nil
+}
+
+type cassette struct {
+	Interactions []*interaction `json:"interactions"`
+}
+
+type interaction struct {
+	Request  recordedRequest  `json:"request"`
+	Response recordedResponse `json:"response"`
+	used     bool
+}
+
+type recordedRequest struct {
+	Method string `json:"method"`
+	// URL holds the path and query only, so that the cassette does not depend on the
+	// organization it was recorded with.
+	URL  string `json:"url"`
+	Body string `json:"body,omitempty"`
+}
+
+type recordedResponse struct {
+	StatusCode int               `json:"status_code"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	Body       string            `json:"body,omitempty"`
+}
+
+func loadCassette(path string) (*cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var c cassette
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &c, nil
+}
+
+func (c *cassette) save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// cassetteTransport records the requests sent through it to a cassette, or answers
+// them from a cassette without sending them.
+type cassetteTransport struct {
+	next http.RoundTripper
+
+	mu        sync.Mutex
+	cassette  *cassette
+	recording bool
+}
+
+func (t *cassetteTransport) record() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.cassette, t.recording = &cassette{}, true
+}
+
+func (t *cassetteTransport) replay(c *cassette) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.cassette, t.recording = c, false
+}
+
+func (t *cassetteTransport) stop() *cassette {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	c := t.cassette
+	t.cassette, t.recording = nil, false
+	return c
+}
+
+func (t *cassetteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	c, recording := t.cassette, t.recording
+	t.mu.Unlock()
+	if c == nil {
+		return t.next.RoundTrip(req)
+	}
+
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	recorded := recordedRequest{
+		Method: req.Method,
+		URL:    req.URL.RequestURI(),
+		Body:   redactBody(string(body)),
+	}
+	if !recording {
+		return t.replayRequest(c, req, recorded)
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+	headers := map[string]string{}
+	for _, name := range []string{"Content-Type", "Retry-After"} {
+		if v := resp.Header.Get(name); v != "" {
+			headers[name] = v
+		}
+	}
+	t.mu.Lock()
+	c.Interactions = append(c.Interactions, &interaction{
+		Request: recorded,
+		Response: recordedResponse{
+			StatusCode: resp.StatusCode,
+			Headers:    headers,
+			Body:       redactBody(string(respBody)),
+		},
+	})
+	t.mu.Unlock()
+	return resp, nil
+}
+
+// replayRequest answers req with the first unused interaction recorded for the same
+// request. Terraform may reorder independent requests, so they are not replayed
+// strictly in sequence.
+func (t *cassetteTransport) replayRequest(c *cassette, req *http.Request, recorded recordedRequest) (*http.Response, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, i := range c.Interactions {
+		if i.used || i.Request.Method != recorded.Method || i.Request.URL != recorded.URL || !sameBody(i.Request.Body, recorded.Body) {
+			continue
+		}
+		i.used = true
+		header := http.Header{}
+		for name, value := range i.Response.Headers {
+			header.Set(name, value)
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", i.Response.StatusCode, http.StatusText(i.Response.StatusCode)),
+			StatusCode:    i.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          io.NopCloser(strings.NewReader(i.Response.Body)),
+			ContentLength: int64(len(i.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("the cassette has no unused interaction for %s %s", recorded.Method, recorded.URL)
+}
+
+func sameBody(a, b string) bool {
+	if a == b {
+		return true
+	}
+	var va, vb interface{}
+	if json.Unmarshal([]byte(a), &va) != nil || json.Unmarshal([]byte(b), &vb) != nil {
+		return false
+	}
+	ca, _ := json.Marshal(va)
+	cb, _ := json.Marshal(vb)
+	return bytes.Equal(ca, cb)
+}
+
+func TestCassetteTransport_recordAndReplay(t *testing.T) {
+	server := fakeapi.NewServer()
+	recorder := &cassetteTransport{next: http.DefaultTransport}
+	recorder.record()
+	c, err := newClient(context.Background(), server.URL, server.ClientID, server.ClientSecret, recorder)
+	require.NoError(t, err)
+
+	network, err := c.client.Networks.Create(cloudconnexa.Network{
+		Name:       "network",
+		Connectors: []cloudconnexa.NetworkConnector{{Name: "connector", VpnRegionId: "fi-hel"}},
+	})
+	require.NoError(t, err)
+	profile, err := c.client.Connectors.GetProfile(network.Connectors[0].Id)
+	require.NoError(t, err)
+	require.Contains(t, profile, "fake-private-key")
+	server.Close()
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	require.NoError(t, recorder.stop().save(path))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), fakeapi.AccessToken)
+	assert.NotContains(t, string(data), server.ClientSecret)
+	assert.NotContains(t, string(data), "fake-private-key")
+	assert.NotContains(t, string(data), strings.TrimPrefix(server.URL, "http://"))
+
+	cassette, err := loadCassette(path)
+	require.NoError(t, err)
+	player := &cassetteTransport{next: http.DefaultTransport}
+	player.replay(cassette)
+	c, err = newClient(context.Background(), "https://replay.api.openvpn.com", "id", "secret", player)
+	require.NoError(t, err)
+	assert.Equal(t, redacted, c.client.Token)
+
+	replayed, err := c.client.Networks.Create(cloudconnexa.Network{
+		Name:       "network",
+		Connectors: []cloudconnexa.NetworkConnector{{Name: "connector", VpnRegionId: "fi-hel"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, network.Id, replayed.Id)
+	profile, err = c.client.Connectors.GetProfile(network.Connectors[0].Id)
+	require.NoError(t, err)
+	assert.Contains(t, profile, "<key>\n"+redacted+"\n</key>")
+
+	_, err = c.client.Connectors.GetProfile(network.Connectors[0].Id)
+	assert.ErrorContains(t, err, "no unused interaction", "every interaction is replayed once")
+}
+
+func TestTestAccRandom_isReproducible(t *testing.T) {
+	a := testAccRandom{rand.New(rand.NewSource(42))}
+	b := testAccRandom{rand.New(rand.NewSource(42))}
+	assert.Equal(t, a.RandomWithPrefix("test"), b.RandomWithPrefix("test"))
+	assert.Equal(t, a.RandString(10), b.RandString(10))
+	name := a.RandStringFromCharSet(10, alphabet)
+	assert.Equal(t, name, b.RandStringFromCharSet(10, alphabet))
+	assert.Regexp(t, "^[a-z]{10}$", name)
+
+	_, subnet, err := net.ParseCIDR("10.0.0.0/8")
+	require.NoError(t, err)
+	for i := 0; i < 20; i++ {
+		ip, err := a.RandIpAddress("10.0.0.0/8")
+		require.NoError(t, err)
+		assert.True(t, subnet.Contains(net.ParseIP(ip)), ip)
+	}
+}
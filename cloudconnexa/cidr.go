@@ -0,0 +1,24 @@
+package cloudconnexa
+
+import (
+	"net"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// canonicalCIDR returns the network of a CIDR, which is the form the API stores: the
+// host bits are cleared and IPv6 addresses are compressed. Other values, such as the
+// domains of DOMAIN routes, are returned unchanged.
+func canonicalCIDR(value string) string {
+	if _, network, err := net.ParseCIDR(value); err == nil {
+		return network.String()
+	}
+	return value
+}
+
+// suppressEquivalentCIDRs is the DiffSuppressFunc of subnet attributes. It hides the
+// difference between CIDRs of the same network, such as `10.0.0.5/24` in the
+// configuration and `10.0.0.0/24` in the state.
+func suppressEquivalentCIDRs(_, old, new string, _ *schema.ResourceData) bool {
+	return old != "" && canonicalCIDR(old) == canonicalCIDR(new)
+}
@@ -0,0 +1,186 @@
+package cloudconnexa
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/openvpn/cloudconnexa-go-client/v2/cloudconnexa"
+)
+
+// routedSubnet is a subnet that Cloud Connexa already routes, along with the object
+// that routes it.
+type routedSubnet struct {
+	subnet *net.IPNet
+	// networkId and routeId are empty for the system subnets of user groups.
+	networkId string
+	routeId   string
+	owner     string
+}
+
+// listRoutedSubnets returns the subnets of the routes of every network and the system
+// subnets of every user group.
+func listRoutedSubnets(c *cloudconnexa.Client) ([]routedSubnet, error) {
+	var subnets []routedSubnet
+	networks, err := c.Networks.List()
+	if err != nil {
+		return nil, err
+	}
+	for _, network := range networks {
+		routes, err := listRoutes(c, network.Id)
+		if err != nil {
+			return nil, err
+		}
+		for _, route := range routes {
+			if _, subnet, err := net.ParseCIDR(route.Subnet); err == nil {
+				subnets = append(subnets, routedSubnet{
+					subnet:    subnet,
+					networkId: network.Id,
+					routeId:   route.Id,
+					owner:     fmt.Sprintf("route %s of network %q", route.Id, network.Name),
+				})
+			}
+		}
+	}
+	userGroups, err := c.UserGroups.List()
+	if err != nil {
+		return nil, err
+	}
+	for _, userGroup := range userGroups {
+		for _, systemSubnet := range userGroup.SystemSubnets {
+			if _, subnet, err := net.ParseCIDR(systemSubnet); err == nil {
+				subnets = append(subnets, routedSubnet{
+					subnet: subnet,
+					owner:  fmt.Sprintf("system subnets of user group %q", userGroup.Name),
+				})
+			}
+		}
+	}
+	return subnets, nil
+}
+
+// routedSubnetsSnapshot holds the subnets routed by Cloud Connexa for the whole run of
+// the provider, which Terraform starts anew for every plan and apply. Routes, networks,
+// network routes and IP services check them when planned, and listing them takes a
+// request per network. Objects created during the run are therefore not checked against.
+type routedSubnetsSnapshot struct {
+	mu      sync.Mutex
+	subnets []routedSubnet
+	listed  bool
+}
+
+// get lists the routed subnets on its first call and returns the same list afterwards.
+// A failed listing is not kept, so that the next check tries again.
+func (s *routedSubnetsSnapshot) get(c *cloudconnexa.Client) ([]routedSubnet, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.listed {
+		subnets, err := listRoutedSubnets(c)
+		if err != nil {
+			return nil, err
+		}
+		s.subnets, s.listed = subnets, true
+	}
+	return s.subnets, nil
+}
+
+// checkSubnetOverlaps reports the values of attribute that overlap a subnet already
+// routed by Cloud Connexa, except the subnets for which ignore returns true. Values that
+// are not CIDRs, such as domain routes, are not checked.
+func checkSubnetOverlaps(ctx context.Context, m interface{}, attribute string, values []string, ignore func(routedSubnet) bool) error {
+	var subnets []*net.IPNet
+	for _, value := range values {
+		if _, subnet, err := net.ParseCIDR(value); err == nil {
+			subnets = append(subnets, subnet)
+		}
+	}
+	if len(subnets) == 0 {
+		return nil
+	}
+
+	meta := m.(*apiClient)
+	routed, err := meta.routedSubnets.get(meta.withContext(ctx))
+	if err != nil {
+		return fmt.Errorf("listing the existing routes to check %s for overlaps: %w", attribute, err)
+	}
+	var errs []error
+	for _, subnet := range subnets {
+		for _, r := range routed {
+			if ignore(r) || !subnetsOverlap(subnet, r.subnet) {
+				continue
+			}
+			errs = append(errs, fmt.Errorf("%s: %s overlaps %s, which is routed by the %s", attribute, subnet, r.subnet, r.owner))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func subnetsOverlap(a, b *net.IPNet) bool {
+	return a.Contains(b.IP) || b.Contains(a.IP)
+}
+
+// priorId returns the ID of the object being planned. The SDK plans replacements a
+// second time without the prior state, so the ID is then read from the raw state.
+func priorId(d *schema.ResourceDiff) string {
+	if id := d.Id(); id != "" {
+		return id
+	}
+	state := d.GetRawState()
+	if state.IsNull() || !state.IsKnown() || !state.Type().IsObjectType() || !state.Type().HasAttribute("id") {
+		return ""
+	}
+	id := state.GetAttr("id")
+	if id.IsNull() || !id.IsKnown() {
+		return ""
+	}
+	return id.AsString()
+}
+
+func customizeDiffRoute(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if !d.HasChange("subnet") || !d.NewValueKnown("subnet") {
+		return nil
+	}
+	// Routes of the same network may overlap each other, as with network routes.
+	routeId := priorId(d)
+	networkItemId := d.Get("network_item_id").(string)
+	return checkSubnetOverlaps(ctx, m, "subnet", []string{d.Get("subnet").(string)}, func(r routedSubnet) bool {
+		return (routeId != "" && r.routeId == routeId) || (r.networkId != "" && r.networkId == networkItemId)
+	})
+}
+
+func customizeDiffNetwork(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if !d.HasChange("default_route.0.subnet") || !d.NewValueKnown("default_route.0.subnet") {
+		return nil
+	}
+	// The default route may overlap the other routes of the network.
+	networkId := priorId(d)
+	return checkSubnetOverlaps(ctx, m, "default_route.0.subnet", []string{d.Get("default_route.0.subnet").(string)}, func(r routedSubnet) bool {
+		return networkId != "" && r.networkId == networkId
+	})
+}
+
+func customizeDiffIPService(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if !d.HasChange("routes") || !d.NewValueKnown("routes") {
+		return nil
+	}
+	old, new := d.GetChange("routes")
+	existing := map[string]bool{}
+	for _, value := range old.([]interface{}) {
+		existing[canonicalCIDR(value.(string))] = true
+	}
+	var added []string
+	for _, value := range new.([]interface{}) {
+		if !existing[canonicalCIDR(value.(string))] {
+			added = append(added, value.(string))
+		}
+	}
+	// The routes of a service are expected to fall within the routes of its own network.
+	// Its domain routes are skipped by checkSubnetOverlaps.
+	networkItemId := d.Get("network_item_id").(string)
+	return checkSubnetOverlaps(ctx, m, "routes", added, func(r routedSubnet) bool {
+		return r.networkId != "" && r.networkId == networkItemId
+	})
+}
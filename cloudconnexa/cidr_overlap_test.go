@@ -0,0 +1,161 @@
+package cloudconnexa
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/OpenVPN/terraform-provider-openvpn-cloud/internal/fakeapi"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/openvpn/cloudconnexa-go-client/v2/cloudconnexa"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCustomizeDiff_reportsOverlappingSubnets(t *testing.T) {
+	server := fakeapi.NewServer()
+	defer server.Close()
+	p := newTestProvider(t, server)
+	meta := p.Meta().(*apiClient)
+	ctx := context.Background()
+
+	prod, err := meta.client.Networks.Create(cloudconnexa.Network{
+		Name:       "prod",
+		Connectors: []cloudconnexa.NetworkConnector{{Name: "prod", VpnRegionId: "fi-hel"}},
+	})
+	require.NoError(t, err)
+	prodRoute, err := meta.client.Routes.Create(prod.Id, cloudconnexa.Route{Type: "IP_V4", Subnet: "10.1.0.0/16"})
+	require.NoError(t, err)
+	staging, err := meta.client.Networks.Create(cloudconnexa.Network{
+		Name:       "staging",
+		Connectors: []cloudconnexa.NetworkConnector{{Name: "staging", VpnRegionId: "fi-hel"}},
+	})
+	require.NoError(t, err)
+
+	plan := func(resource string, state *terraform.InstanceState, raw map[string]interface{}) error {
+		_, err := p.ResourcesMap[resource].Diff(ctx, state, terraform.NewResourceConfigRaw(raw), meta)
+		return err
+	}
+	route := func(subnet string) map[string]interface{} {
+		return map[string]interface{}{"type": "IP_V4", "subnet": subnet, "network_item_id": staging.Id}
+	}
+	network := func(subnet string) map[string]interface{} {
+		return map[string]interface{}{
+			"name":          "other",
+			"connector":     []interface{}{map[string]interface{}{"name": "west", "vpn_region_id": "us-west-1"}},
+			"default_route": []interface{}{map[string]interface{}{"subnet": subnet}},
+		}
+	}
+	service := func(networkId string, routes ...interface{}) map[string]interface{} {
+		return map[string]interface{}{
+			"name":              "service",
+			"type":              "SERVICE_DESTINATION",
+			"routes":            routes,
+			"network_item_type": "NETWORK",
+			"network_item_id":   networkId,
+		}
+	}
+
+	err = plan("cloudconnexa_route", nil, route("10.1.2.0/24"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `subnet: 10.1.2.0/24 overlaps 10.1.0.0/16, which is routed by the route `+prodRoute.Id+` of network "prod"`)
+	err = plan("cloudconnexa_route", nil, route("100.100.0.0/16"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `overlaps 100.96.0.0/11, which is routed by the system subnets of user group "Default"`)
+	assert.NoError(t, plan("cloudconnexa_route", nil, route("10.2.0.0/16")))
+	prodRouteConfig := route("10.1.2.0/24")
+	prodRouteConfig["network_item_id"] = prod.Id
+	assert.NoError(t, plan("cloudconnexa_route", nil, prodRouteConfig), "a route may overlap the routes of its own network")
+
+	err = plan("cloudconnexa_network", nil, network("10.0.0.0/8"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `default_route.0.subnet: 10.0.0.0/8 overlaps 10.1.0.0/16`)
+	assert.NoError(t, plan("cloudconnexa_network", nil, network("10.2.0.0/16")))
+
+	err = plan("cloudconnexa_ip_service", nil, service(staging.Id, "10.2.0.0/16", "10.1.0.1/32"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `routes: 10.1.0.1/32 overlaps 10.1.0.0/16`)
+	assert.NotContains(t, err.Error(), "10.2.0.0/16")
+	assert.NoError(t, plan("cloudconnexa_ip_service", nil, service(prod.Id, "10.1.0.1/32")), "a service may use the routes of its own network")
+}
+
+func TestCustomizeDiff_ignoresTheObjectItself(t *testing.T) {
+	server := fakeapi.NewServer()
+	defer server.Close()
+	p := newTestProvider(t, server)
+	meta := p.Meta().(*apiClient)
+
+	r := p.ResourcesMap["cloudconnexa_network"]
+	config := func(subnet string) map[string]interface{} {
+		return map[string]interface{}{
+			"name":          "network",
+			"connector":     []interface{}{map[string]interface{}{"name": "west", "vpn_region_id": "us-west-1"}},
+			"default_route": []interface{}{map[string]interface{}{"subnet": subnet}},
+		}
+	}
+	// Terraform starts the provider anew for every plan and apply, which lists the
+	// routed subnets again.
+	newRun := func() { meta.routedSubnets = routedSubnetsSnapshot{} }
+	state := testApply(t, r, nil, config("10.1.0.0/16"), meta)
+	newRun()
+	// Growing the default route must not conflict with the route it replaces.
+	state = testApply(t, r, state, config("10.0.0.0/8"), meta)
+
+	routes := p.ResourcesMap["cloudconnexa_route"]
+	routeConfig := func(subnet string) map[string]interface{} {
+		return map[string]interface{}{"type": "IP_V4", "subnet": subnet, "network_item_id": state.ID}
+	}
+	routeState := testApply(t, routes, nil, routeConfig("172.16.0.0/16"), meta)
+	newRun()
+	// Terraform sends the prior state along with the plan request.
+	routeState.RawState = cty.ObjectVal(map[string]cty.Value{"id": cty.StringVal(routeState.ID)})
+	// Neither must a route that is replaced by a narrower one.
+	testApply(t, routes, routeState, routeConfig("172.16.0.0/24"), meta)
+}
+
+func TestCustomizeDiff_listsRoutedSubnetsOnce(t *testing.T) {
+	server := fakeapi.NewServer()
+	defer server.Close()
+	p := newTestProvider(t, server)
+	meta := p.Meta().(*apiClient)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		network, err := meta.client.Networks.Create(cloudconnexa.Network{
+			Name:       fmt.Sprintf("network-%d", i),
+			Connectors: []cloudconnexa.NetworkConnector{{Name: fmt.Sprintf("connector-%d", i), VpnRegionId: "fi-hel"}},
+		})
+		require.NoError(t, err)
+		_, err = meta.client.Routes.Create(network.Id, cloudconnexa.Route{Type: "IP_V4", Subnet: fmt.Sprintf("10.%d.0.0/16", i)})
+		require.NoError(t, err)
+	}
+
+	r := p.ResourcesMap["cloudconnexa_route"]
+	plan := func(subnet string) error {
+		_, err := r.Diff(ctx, nil, terraform.NewResourceConfigRaw(map[string]interface{}{
+			"type": "IP_V4", "subnet": subnet, "network_item_id": "other",
+		}), meta)
+		return err
+	}
+	require.NoError(t, plan("172.16.0.0/24"))
+	listed := server.Requests()
+	var wg sync.WaitGroup
+	errs := make([]error, 10)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = plan(fmt.Sprintf("172.16.%d.0/24", i+1))
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		assert.NoError(t, err)
+	}
+	err := plan("10.2.1.0/24")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "overlaps 10.2.0.0/16")
+	assert.Equal(t, listed, server.Requests(), "the routed subnets must only be listed for the first plan")
+}
@@ -0,0 +1,140 @@
+package cloudconnexa
+
+import (
+	"context"
+	"testing"
+
+	"github.com/OpenVPN/terraform-provider-openvpn-cloud/internal/fakeapi"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/openvpn/cloudconnexa-go-client/v2/cloudconnexa"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSuppressEquivalentCIDRs(t *testing.T) {
+	for _, tc := range []struct {
+		old, new string
+		suppress bool
+	}{
+		{"10.0.0.0/24", "10.0.0.5/24", true},
+		{"10.0.0.0/24", "10.0.0.0/24", true},
+		{"fd00::/64", "FD00:0:0:0::1/64", true},
+		{"10.0.0.0/24", "10.0.0.0/16", false},
+		{"10.0.0.0/24", "10.0.1.0/24", false},
+		{"example.com", "example.com", true},
+		{"example.com", "app.example.com", false},
+		{"", "10.0.0.0/24", false},
+	} {
+		assert.Equal(t, tc.suppress, suppressEquivalentCIDRs("subnet", tc.old, tc.new, nil), "%q -> %q", tc.old, tc.new)
+	}
+}
+
+func TestSubnetAttributes_equivalentCIDRsPlanNoChanges(t *testing.T) {
+	server := fakeapi.NewServer()
+	defer server.Close()
+	p := newTestProvider(t, server)
+	meta := p.Meta().(*apiClient)
+	ctx := context.Background()
+
+	network, err := meta.client.Networks.Create(cloudconnexa.Network{
+		Name:       "network",
+		Connectors: []cloudconnexa.NetworkConnector{{Name: "network", VpnRegionId: "fi-hel"}},
+	})
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		resource string
+		raw      map[string]interface{}
+		key      string
+		stored   string
+	}{
+		{"cloudconnexa_route", map[string]interface{}{
+			"type": "IP_V4", "subnet": "10.20.0.5/16", "network_item_id": network.Id,
+		}, "subnet", "10.20.0.0/16"},
+		{"cloudconnexa_route", map[string]interface{}{
+			"type": "IP_V6", "subnet": "FD00:0:0:1::1/64", "network_item_id": network.Id,
+		}, "subnet", "fd00:0:0:1::/64"},
+		{"cloudconnexa_network", map[string]interface{}{
+			"name":          "other",
+			"connector":     []interface{}{map[string]interface{}{"name": "west", "vpn_region_id": "us-west-1"}},
+			"default_route": []interface{}{map[string]interface{}{"subnet": "10.30.1.1/16"}},
+		}, "default_route.0.subnet", "10.30.0.0/16"},
+		{"cloudconnexa_ip_service", map[string]interface{}{
+			"name":              "service",
+			"type":              "SERVICE_DESTINATION",
+			"routes":            []interface{}{"10.20.1.1/24"},
+			"config":            []interface{}{map[string]interface{}{"service_types": []interface{}{"ANY"}}},
+			"network_item_type": "NETWORK",
+			"network_item_id":   network.Id,
+		}, "routes.0", "10.20.1.0/24"},
+		{"cloudconnexa_user_group", map[string]interface{}{
+			"name":           "group",
+			"vpn_region_ids": []interface{}{"fi-hel"},
+			"system_subnets": []interface{}{"100.70.3.4/24"},
+		}, "system_subnets.0", "100.70.3.0/24"},
+	} {
+		r := p.ResourcesMap[tc.resource]
+		state := testApply(t, r, nil, tc.raw, meta)
+		state, diags := r.RefreshWithoutUpgrade(ctx, state, meta)
+		require.False(t, diags.HasError(), "%s: %v", tc.resource, diags)
+		assert.Equal(t, tc.stored, r.Data(state).Get(tc.key), "%s must store the canonical form", tc.resource)
+		diff, err := r.Diff(ctx, state, terraform.NewResourceConfigRaw(tc.raw), meta)
+		require.NoError(t, err)
+		assert.Nil(t, diff, "%s: equivalent CIDRs must not plan changes", tc.resource)
+	}
+}
+
+func TestSubnetAttributes_rejectInvalidCIDRs(t *testing.T) {
+	resources := Provider().ResourcesMap
+	for _, tc := range []struct {
+		resource string
+		raw      map[string]interface{}
+	}{
+		{"cloudconnexa_ip_service", map[string]interface{}{
+			"name":              "service",
+			"type":              "SERVICE_DESTINATION",
+			"routes":            []interface{}{"10.0.0.0/24", "10.0.0.300/24"},
+			"network_item_type": "NETWORK",
+			"network_item_id":   "network",
+		}},
+		{"cloudconnexa_user_group", map[string]interface{}{
+			"name":           "group",
+			"vpn_region_ids": []interface{}{"fi-hel"},
+			"system_subnets": []interface{}{"100.64.0.0"},
+		}},
+	} {
+		diags := resources[tc.resource].Validate(terraform.NewResourceConfigRaw(tc.raw))
+		assert.True(t, diags.HasError(), tc.resource)
+	}
+
+	diags := resources["cloudconnexa_ip_service"].Validate(terraform.NewResourceConfigRaw(map[string]interface{}{
+		"name":              "service",
+		"type":              "SERVICE_DESTINATION",
+		"routes":            []interface{}{"10.0.0.0/24", "fd00::/64", "test.ua", "*.example.com"},
+		"network_item_type": "NETWORK",
+		"network_item_id":   "network",
+	}))
+	assert.False(t, diags.HasError(), "IP services route domains as well as CIDRs: %v", diags)
+
+	// The type of a route decides which values are valid, so routes are checked when
+	// they are planned.
+	for _, tc := range []struct {
+		resource string
+		raw      map[string]interface{}
+		message  string
+	}{
+		{"cloudconnexa_route", map[string]interface{}{
+			"type": "IP_V4", "subnet": "fd00::/64", "network_item_id": "network",
+		}, `subnet: "fd00::/64" is not a valid IPv4 CIDR`},
+		{"cloudconnexa_network", map[string]interface{}{
+			"name":          "network",
+			"connector":     []interface{}{map[string]interface{}{"name": "west", "vpn_region_id": "us-west-1"}},
+			"default_route": []interface{}{map[string]interface{}{"type": "IP_V6", "subnet": "10.0.0.0/8"}},
+		}, `default_route.0.subnet: "10.0.0.0/8" is not a valid IPv6 CIDR`},
+	} {
+		r := resources[tc.resource]
+		// The client is nil, so the plan fails before any overlap check reaches it.
+		_, err := r.Diff(context.Background(), nil, terraform.NewResourceConfigRaw(tc.raw), &apiClient{})
+		assert.ErrorContains(t, err, tc.message, tc.resource)
+	}
+}
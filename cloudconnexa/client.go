@@ -0,0 +1,166 @@
+package cloudconnexa
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+	"unsafe"
+
+	"github.com/openvpn/cloudconnexa-go-client/v2/cloudconnexa"
+	"golang.org/x/time/rate"
+)
+
+const (
+	clientUserAgent = "terraform-provider-cloudconnexa"
+	// requestTimeout bounds every attempt of a request. It does not cover the waits
+	// between retries nor the wait for the rate limiter, which only the timeout of the
+	// Terraform operation bounds.
+	requestTimeout = 30 * time.Second
+)
+
+// apiClient is the meta value the provider passes to resources and data sources.
+type apiClient struct {
+	client     *cloudconnexa.Client
+	httpClient *http.Client
+	// readOnly makes resources refuse to create, update or delete objects.
+	readOnly bool
+	// routedSubnets lists the routed subnets once for the overlap checks.
+	routedSubnets routedSubnetsSnapshot
+}
+
+// withContext returns a copy of the Cloud Connexa client whose requests are bound to
+// ctx, so that resource timeouts and cancellation interrupt in-flight API calls.
+func (a *apiClient) withContext(ctx context.Context) *cloudconnexa.Client {
+	httpClient := *a.httpClient
+	httpClient.Transport = &contextTransport{ctx: ctx, next: a.httpClient.Transport}
+	c, err := buildClient(a.client.BaseURL, a.client.Token, &httpClient)
+	if err != nil {
+		// Unreachable: the same construction already succeeded for a.client.
+		return a.client
+	}
+	return c
+}
+
+// newClient builds a Cloud Connexa client whose token request and API calls all go
+// through the given transport. It mirrors cloudconnexa.NewClient, which hardcodes its
+// own http.Client and therefore cannot be used with a custom transport.
+func newClient(ctx context.Context, baseUrl, clientId, clientSecret string, transport http.RoundTripper) (*apiClient, error) {
+	if clientId == "" || clientSecret == "" {
+		return nil, cloudconnexa.ErrCredentialsRequired
+	}
+	httpClient := &http.Client{Transport: transport}
+	token, err := fetchToken(ctx, httpClient, baseUrl, clientId, clientSecret)
+	if err != nil {
+		return nil, err
+	}
+	c, err := buildClient(baseUrl, token, httpClient)
+	if err != nil {
+		return nil, err
+	}
+	return &apiClient{client: c, httpClient: httpClient}, nil
+}
+
+func fetchToken(ctx context.Context, httpClient *http.Client, baseUrl, clientId, clientSecret string) (string, error) {
+	jsonData, err := json.Marshal(map[string]string{"grant_type": "client_credentials", "scope": "default"})
+	if err != nil {
+		return "", err
+	}
+	tokenUrl := fmt.Sprintf("%s/api/beta/oauth/token", strings.TrimRight(baseUrl, "/"))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenUrl, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return "", err
+	}
+	req.SetBasicAuth(clientId, clientSecret)
+	// Requesting a token creates nothing, so it is retried like the idempotent requests.
+	// As for http.Transport, a nil Idempotency-Key marks it without sending the header.
+	req.Header["Idempotency-Key"] = nil
+	req.Header.Add("Accept", "application/json")
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", fmt.Errorf("unable to obtain an access token, status code: %d, response body: %s", resp.StatusCode, string(body))
+	}
+	var credentials cloudconnexa.Credentials
+	if err := json.Unmarshal(body, &credentials); err != nil {
+		return "", err
+	}
+	if credentials.AccessToken == "" {
+		return "", errors.New("unable to obtain an access token: the response did not contain one")
+	}
+	return credentials.AccessToken, nil
+}
+
+// buildClient assembles a cloudconnexa.Client around httpClient. The HTTP client and the
+// service back-reference are unexported in the library, so they are set via reflection.
+func buildClient(baseUrl, token string, httpClient *http.Client) (*cloudconnexa.Client, error) {
+	c := &cloudconnexa.Client{
+		BaseURL:   baseUrl,
+		Token:     token,
+		UserAgent: clientUserAgent,
+		// Requests are throttled by the provider's transport, which also covers retries
+		// and the token request, so the library's own limiter is disabled.
+		RateLimiter: rate.NewLimiter(rate.Inf, 0),
+	}
+	v := reflect.ValueOf(c).Elem()
+	httpClientField := v.FieldByName("client")
+	common := v.FieldByName("common")
+	if !httpClientField.IsValid() || !common.IsValid() || !common.FieldByName("client").IsValid() {
+		return nil, errors.New("unsupported cloudconnexa-go-client version: unable to configure the HTTP client")
+	}
+	setUnexportedField(httpClientField, reflect.ValueOf(httpClient))
+	setUnexportedField(common.FieldByName("client"), reflect.ValueOf(c))
+
+	svc := unsafe.Pointer(common.UnsafeAddr())
+	c.Connectors = (*cloudconnexa.ConnectorsService)(svc)
+	c.DnsRecords = (*cloudconnexa.DNSRecordsService)(svc)
+	c.Hosts = (*cloudconnexa.HostsService)(svc)
+	c.IPServices = (*cloudconnexa.IPServicesService)(svc)
+	c.Networks = (*cloudconnexa.NetworksService)(svc)
+	c.Routes = (*cloudconnexa.RoutesService)(svc)
+	c.Users = (*cloudconnexa.UsersService)(svc)
+	c.UserGroups = (*cloudconnexa.UserGroupsService)(svc)
+	c.VPNRegions = (*cloudconnexa.VPNRegionsService)(svc)
+	return c, nil
+}
+
+func setUnexportedField(field reflect.Value, value reflect.Value) {
+	reflect.NewAt(field.Type(), unsafe.Pointer(field.UnsafeAddr())).Elem().Set(value)
+}
+
+// doJSONRequest sends body as JSON and decodes the response into response, for the
+// requests that the Cloud Connexa client does not support or gets wrong.
+func doJSONRequest(c *cloudconnexa.Client, method, url string, body, response interface{}) error {
+	var reqBody bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&reqBody).Encode(body); err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequest(method, url, &reqBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	respBody, err := c.DoRequest(req)
+	if err != nil {
+		return err
+	}
+	if response == nil {
+		return nil
+	}
+	return json.Unmarshal(respBody, response)
+}
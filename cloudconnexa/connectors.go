@@ -0,0 +1,134 @@
+package cloudconnexa
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/openvpn/cloudconnexa-go-client/v2/cloudconnexa"
+)
+
+// connectorPollInterval is the time between two checks of the status of the connectors
+// being waited for.
+var connectorPollInterval = 10 * time.Second
+
+// describedConnector is a connector as returned by the API. cloudconnexa.Connector has
+// no field for the description and the client cannot update connectors, so the
+// provider makes the connector requests itself.
+type describedConnector struct {
+	cloudconnexa.Connector
+	Description string `json:"description"`
+}
+
+type describedConnectorPage struct {
+	Content    []describedConnector `json:"content"`
+	TotalPages int                  `json:"totalPages"`
+}
+
+// connectorRequest is the body of the requests that create and update connectors.
+type connectorRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	VpnRegionId string `json:"vpnRegionId"`
+}
+
+func listConnectors(c *cloudconnexa.Client) ([]describedConnector, error) {
+	var connectors []describedConnector
+	for page := 0; ; page++ {
+		var response describedConnectorPage
+		err := doJSONRequest(c, http.MethodGet, fmt.Sprintf("%s/api/beta/connectors/page?page=%d&size=%d", c.BaseURL, page, 10), nil, &response)
+		if err != nil {
+			return nil, err
+		}
+		connectors = append(connectors, response.Content...)
+		if page+1 >= response.TotalPages {
+			return connectors, nil
+		}
+	}
+}
+
+// getConnector returns the connector with the given ID, or nil if there is none. Like
+// Connectors.GetByID of the client library, it looks for the connector in the listing.
+func getConnector(c *cloudconnexa.Client, connectorId string) (*describedConnector, error) {
+	connectors, err := listConnectors(c)
+	if err != nil {
+		return nil, err
+	}
+	for _, connector := range connectors {
+		if connector.Id == connectorId {
+			return &connector, nil
+		}
+	}
+	return nil, nil
+}
+
+func createConnector(c *cloudconnexa.Client, networkItemType, networkItemId string, request connectorRequest) (*describedConnector, error) {
+	var connector describedConnector
+	err := doJSONRequest(c, http.MethodPost, fmt.Sprintf("%s/api/beta/connectors?networkItemId=%s&networkItemType=%s", c.BaseURL, networkItemId, networkItemType), request, &connector)
+	if err != nil {
+		return nil, err
+	}
+	return &connector, nil
+}
+
+func updateConnector(c *cloudconnexa.Client, connectorId, networkItemType, networkItemId string, request connectorRequest) error {
+	return doJSONRequest(c, http.MethodPut, fmt.Sprintf("%s/api/beta/connectors/%s?networkItemId=%s&networkItemType=%s", c.BaseURL, connectorId, networkItemId, networkItemType), request, nil)
+}
+
+// waitForOnlineSchema returns the `wait_for_online` attribute of connectors.
+func waitForOnlineSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeBool,
+		Optional: true,
+		Default:  false,
+		Description: "Whether Terraform waits for the connector to report online after creating it, " +
+			"until the `create` timeout of the resource expires, or its `update` timeout for connectors added to an existing resource. " +
+			"The connector must be deployed in the meantime by a process that does not depend on this resource, as provisioners and the resources that use its profile only run once it has been created. Defaults to `false`.",
+	}
+}
+
+// waitForConnectorsOnline polls the connectors with the given IDs until they all report
+// online. The context carries the timeout of the Terraform operation.
+func waitForConnectorsOnline(ctx context.Context, c *cloudconnexa.Client, connectorIds []string) error {
+	if len(connectorIds) == 0 {
+		return nil
+	}
+	offline := connectorIds
+	for {
+		connectors, err := listConnectors(c)
+		if err != nil && ctx.Err() != nil {
+			// The timeout interrupted the request.
+			return fmt.Errorf("connectors %s did not report online before the timeout: %w", strings.Join(offline, ", "), ctx.Err())
+		}
+		if err != nil {
+			return err
+		}
+		statuses := map[string]cloudconnexa.ConnectionStatus{}
+		for _, connector := range connectors {
+			statuses[connector.Id] = connector.ConnectionStatus
+		}
+		offline = nil
+		for _, id := range connectorIds {
+			status, ok := statuses[id]
+			if !ok {
+				return fmt.Errorf("connector %s was deleted while waiting for it to be online", id)
+			}
+			if status != "online" {
+				offline = append(offline, fmt.Sprintf("%s (%s)", id, status))
+			}
+		}
+		if len(offline) == 0 {
+			return nil
+		}
+		sort.Strings(offline)
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("connectors %s did not report online before the timeout: %w", strings.Join(offline, ", "), ctx.Err())
+		case <-time.After(connectorPollInterval):
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package cloudconnexa
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/OpenVPN/terraform-provider-openvpn-cloud/internal/fakeapi"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWaitForOnline(t *testing.T) {
+	defer func(interval time.Duration) { connectorPollInterval = interval }(connectorPollInterval)
+	connectorPollInterval = time.Millisecond
+
+	server := fakeapi.NewServer()
+	defer server.Close()
+	server.ConnectorOnlineAfter = 3
+	p := newTestProvider(t, server)
+	meta := p.Meta().(*apiClient)
+
+	planNoChanges := func(r *schema.Resource, state *terraform.InstanceState, raw map[string]interface{}) {
+		state, diags := r.RefreshWithoutUpgrade(context.Background(), state, meta)
+		require.False(t, diags.HasError(), "%v", diags)
+		diff, err := r.Diff(context.Background(), state, terraform.NewResourceConfigRaw(raw), meta)
+		require.NoError(t, err)
+		assert.Nil(t, diff)
+	}
+	connectorBlock := func(name, region string, wait bool) map[string]interface{} {
+		return map[string]interface{}{"name": name, "vpn_region_id": region, "wait_for_online": wait}
+	}
+
+	networks := p.ResourcesMap["cloudconnexa_network"]
+	networkConfig := func(connectors ...interface{}) map[string]interface{} {
+		return map[string]interface{}{
+			"name":          "network",
+			"connector":     connectors,
+			"default_route": []interface{}{map[string]interface{}{"subnet": "10.1.0.0/16"}},
+		}
+	}
+	raw := networkConfig(connectorBlock("west", "us-west-1", true), connectorBlock("east", "us-east-1", false))
+	network := testApply(t, networks, nil, raw, meta)
+	statuses := map[string]string{}
+	for _, v := range networks.Data(network).Get("connector").(*schema.Set).List() {
+		connector := v.(map[string]interface{})
+		statuses[connector["name"].(string)] = connector["connection_status"].(string)
+	}
+	assert.Equal(t, "online", statuses["west"])
+	planNoChanges(networks, network, raw)
+
+	// Connectors added to an existing network are waited for as well.
+	raw = networkConfig(connectorBlock("west", "us-west-1", true), connectorBlock("north", "fi-hel", true))
+	network = testApply(t, networks, network, raw, meta)
+	for _, v := range networks.Data(network).Get("connector").(*schema.Set).List() {
+		connector := v.(map[string]interface{})
+		assert.Equal(t, "online", connector["connection_status"], connector["name"])
+	}
+	planNoChanges(networks, network, raw)
+
+	hosts := p.ResourcesMap["cloudconnexa_host"]
+	raw = map[string]interface{}{
+		"name":      "host",
+		"connector": []interface{}{connectorBlock("host", "us-west-1", true)},
+	}
+	host := testApply(t, hosts, nil, raw, meta)
+	connector := hosts.Data(host).Get("connector").(*schema.Set).List()[0].(map[string]interface{})
+	assert.Equal(t, "online", connector["connection_status"])
+	assert.Equal(t, true, connector["wait_for_online"])
+	planNoChanges(hosts, host, raw)
+
+	connectors := p.ResourcesMap["cloudconnexa_connector"]
+	raw = map[string]interface{}{
+		"name":              "connector",
+		"vpn_region_id":     "us-west-1",
+		"network_item_type": "NETWORK",
+		"network_item_id":   network.ID,
+		"wait_for_online":   true,
+	}
+	state := testApply(t, connectors, nil, raw, meta)
+	assert.Equal(t, "online", state.Attributes["connection_status"])
+	planNoChanges(connectors, state, raw)
+}
+
+func TestWaitForOnline_timeout(t *testing.T) {
+	defer func(interval time.Duration) { connectorPollInterval = interval }(connectorPollInterval)
+	connectorPollInterval = time.Millisecond
+
+	server := fakeapi.NewServer()
+	defer server.Close()
+	p := newTestProvider(t, server)
+	meta := p.Meta().(*apiClient)
+	network := testApply(t, p.ResourcesMap["cloudconnexa_network"], nil, map[string]interface{}{
+		"name":          "network",
+		"connector":     []interface{}{map[string]interface{}{"name": "west", "vpn_region_id": "us-west-1"}},
+		"default_route": []interface{}{map[string]interface{}{"subnet": "10.1.0.0/16"}},
+	}, meta)
+
+	r := p.ResourcesMap["cloudconnexa_connector"]
+	d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"name":              "connector",
+		"vpn_region_id":     "us-west-1",
+		"network_item_type": "NETWORK",
+		"network_item_id":   network.ID,
+		"wait_for_online":   true,
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	diags := r.CreateContext(ctx, d, meta)
+	require.True(t, diags.HasError())
+	assert.Equal(t, "Timed out waiting for the Cloud Connexa API", diags[0].Summary)
+	assert.Contains(t, diags[0].Detail, "connectors "+d.Id()+" (offline) did not report online before the timeout")
+	assert.NotEmpty(t, d.Id(), "the connector must be recorded even though it is not online")
+}
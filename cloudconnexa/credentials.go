@@ -0,0 +1,251 @@
+package cloudconnexa
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"gopkg.in/yaml.v3"
+)
+
+const defaultProfile = "default"
+
+// credentials holds the settings needed to connect to a Cloud Connexa organization.
+type credentials struct {
+	BaseURL      string `yaml:"base_url"`
+	ClientID     string `yaml:"client_id"`
+	ClientSecret string `yaml:"client_secret"`
+}
+
+// credentialsFilePath returns the location of the file holding the named profiles.
+func credentialsFilePath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".cloudconnexa", "credentials"), nil
+}
+
+// credentialSettings are the provider settings a profile supplies, with the environment
+// variables they can also be sourced from.
+var credentialSettings = []struct{ attribute, envVar string }{
+	{"base_url", BaseURLEnvVar},
+	{"client_id", ClientIDEnvVar},
+	{"client_secret", ClientSecretEnvVar},
+}
+
+// resolveCredentials determines the base URL and credentials of the provider. They are
+// taken as a whole from a single source, so that an ID of one organization is never
+// paired with a secret of another:
+//
+//  1. the profile of the credentials file selected by the `profile` attribute or the
+//     CLOUDCONNEXA_PROFILE environment variable, which must set all three settings and
+//     cannot be combined with them in the provider configuration. The
+//     CLOUDCONNEXA_BASE_URL, CLOUDCONNEXA_CLIENT_ID and CLOUDCONNEXA_CLIENT_SECRET
+//     environment variables are ignored.
+//  2. otherwise the provider configuration, then those environment variables, setting
+//     by setting,
+//  3. otherwise, when neither sets any of them, the `default` profile.
+func resolveCredentials(ctx context.Context, d *schema.ResourceData) (credentials, error) {
+	profileName, profileSource := defaultProfile, ""
+	if v, ok := d.GetOk("profile"); ok {
+		profileName, profileSource = v.(string), "provider configuration"
+	} else if v := os.Getenv(ProfileEnvVar); v != "" {
+		profileName, profileSource = v, ProfileEnvVar+" environment variable"
+	}
+
+	if profileSource != "" {
+		for _, s := range credentialSettings {
+			if _, ok := d.GetOk(s.attribute); ok {
+				return credentials{}, fmt.Errorf("%s cannot be set in the provider configuration together with the profile %q (from the %s), which supplies base_url, client_id and client_secret", s.attribute, profileName, profileSource)
+			}
+		}
+		for _, s := range credentialSettings {
+			if os.Getenv(s.envVar) != "" {
+				tflog.Warn(ctx, "Ignoring environment variable in favor of the selected credentials profile", map[string]interface{}{
+					"variable": s.envVar,
+					"profile":  profileName,
+				})
+			}
+		}
+		return loadProfile(ctx, profileName, profileSource)
+	}
+
+	var creds credentials
+	fromConfig := false
+	for _, s := range credentialSettings {
+		value, source := "", "unset"
+		if v, ok := d.GetOk(s.attribute); ok {
+			value, source = v.(string), "provider configuration"
+		} else if v := os.Getenv(s.envVar); v != "" {
+			value, source = v, s.envVar+" environment variable"
+		}
+		if value != "" {
+			fromConfig = true
+		}
+		if err := creds.set(s.attribute, value); err != nil {
+			return credentials{}, err
+		}
+		tflog.Debug(ctx, "Resolved provider setting", map[string]interface{}{
+			"setting": s.attribute,
+			"source":  source,
+		})
+	}
+	if fromConfig {
+		return creds, nil
+	}
+	creds, err := loadProfile(ctx, defaultProfile, "")
+	if errors.Is(err, os.ErrNotExist) {
+		// The credentials file and its default profile are optional.
+		return credentials{}, nil
+	}
+	return creds, err
+}
+
+// loadProfile returns the named profile of the credentials file. A profile selected
+// explicitly, as told by a non-empty source, must set base_url, client_id and
+// client_secret. A missing profile is reported with os.ErrNotExist.
+func loadProfile(ctx context.Context, name, source string) (credentials, error) {
+	path, err := credentialsFilePath()
+	if err != nil {
+		return credentials{}, err
+	}
+	profiles, err := loadProfiles(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return credentials{}, err
+	}
+	profile, found := profiles[name]
+	if !found {
+		if source == "" {
+			return credentials{}, fmt.Errorf("profile %q not found in %s: %w", name, path, os.ErrNotExist)
+		}
+		return credentials{}, fmt.Errorf("profile %q (from the %s) not found in %s: %w", name, source, path, os.ErrNotExist)
+	}
+	for _, s := range credentialSettings {
+		value, err := profile.get(s.attribute)
+		if err != nil {
+			return credentials{}, err
+		}
+		if value == "" {
+			return credentials{}, fmt.Errorf("profile %q of %s does not set %s", name, path, s.attribute)
+		}
+	}
+	if source == "" {
+		source = "default"
+	}
+	tflog.Debug(ctx, "Loaded Cloud Connexa credentials profile", map[string]interface{}{
+		"profile": name,
+		"source":  source,
+		"path":    path,
+	})
+	return profile, nil
+}
+
+func (c *credentials) get(attribute string) (string, error) {
+	switch attribute {
+	case "base_url":
+		return c.BaseURL, nil
+	case "client_id":
+		return c.ClientID, nil
+	case "client_secret":
+		return c.ClientSecret, nil
+	default:
+		return "", fmt.Errorf("unknown credential setting %q", attribute)
+	}
+}
+
+func (c *credentials) set(attribute, value string) error {
+	switch attribute {
+	case "base_url":
+		c.BaseURL = value
+	case "client_id":
+		c.ClientID = value
+	case "client_secret":
+		c.ClientSecret = value
+	default:
+		return fmt.Errorf("unknown credential setting %q", attribute)
+	}
+	return nil
+}
+
+// loadProfiles reads the named profiles of a credentials file, which may be written
+// either as INI with one section per profile or as YAML with one mapping per profile.
+func loadProfiles(path string) (map[string]credentials, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var profiles map[string]credentials
+	if isINI(data) {
+		profiles, err = parseINIProfiles(data)
+	} else {
+		profiles, err = parseYAMLProfiles(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %w", path, err)
+	}
+	return profiles, nil
+}
+
+// isINI reports whether the first meaningful line of data is an INI section header.
+func isINI(data []byte) bool {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		return strings.HasPrefix(line, "[")
+	}
+	return false
+}
+
+func parseINIProfiles(data []byte) (map[string]credentials, error) {
+	profiles := map[string]credentials{}
+	var section string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			// Also accept the `[profile name]` form used by other tools.
+			section = strings.TrimSpace(strings.TrimPrefix(section, "profile "))
+			profiles[section] = credentials{}
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok || section == "" {
+			return nil, fmt.Errorf("line %d: expected a [profile] header or a key = value pair", n)
+		}
+		value = strings.Trim(strings.TrimSpace(value), `"'`)
+		p := profiles[section]
+		if err := p.set(strings.TrimSpace(key), value); err != nil {
+			return nil, fmt.Errorf("line %d: unknown key %q", n, strings.TrimSpace(key))
+		}
+		profiles[section] = p
+	}
+	return profiles, scanner.Err()
+}
+
+// parseYAMLProfiles rejects unknown keys, as parseINIProfiles does, so that a misspelled
+// setting is reported rather than left unset.
+func parseYAMLProfiles(data []byte) (map[string]credentials, error) {
+	var profiles map[string]credentials
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&profiles); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	return profiles, nil
+}
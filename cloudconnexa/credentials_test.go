@@ -0,0 +1,154 @@
+package cloudconnexa
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testINICredentials = `# Cloud Connexa organizations
+[default]
+base_url      = https://dev.api.openvpn.com
+client_id     = dev-id
+client_secret = dev-secret
+
+[profile prod]
+base_url      = "https://prod.api.openvpn.com"
+client_id     = prod-id
+client_secret = prod-secret
+`
+
+const testYAMLCredentials = `default:
+  base_url: https://dev.api.openvpn.com
+  client_id: dev-id
+  client_secret: dev-secret
+prod:
+  base_url: https://prod.api.openvpn.com
+  client_id: prod-id
+  client_secret: prod-secret
+`
+
+// setUpCredentialsFile writes a credentials file to a fresh home directory and clears
+// the environment variables that take precedence over it.
+func setUpCredentialsFile(t *testing.T, content string) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	for _, v := range []string{BaseURLEnvVar, ClientIDEnvVar, ClientSecretEnvVar, ProfileEnvVar} {
+		t.Setenv(v, "")
+	}
+	if content == "" {
+		return
+	}
+	require.NoError(t, os.MkdirAll(filepath.Join(home, ".cloudconnexa"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".cloudconnexa", "credentials"), []byte(content), 0o600))
+}
+
+func resolveTestCredentials(t *testing.T, raw map[string]interface{}) (credentials, error) {
+	d := schema.TestResourceDataRaw(t, Provider().Schema, raw)
+	return resolveCredentials(context.Background(), d)
+}
+
+func TestResolveCredentials_profiles(t *testing.T) {
+	for name, content := range map[string]string{"ini": testINICredentials, "yaml": testYAMLCredentials} {
+		t.Run(name, func(t *testing.T) {
+			setUpCredentialsFile(t, content)
+
+			creds, err := resolveTestCredentials(t, map[string]interface{}{})
+			require.NoError(t, err)
+			assert.Equal(t, credentials{BaseURL: "https://dev.api.openvpn.com", ClientID: "dev-id", ClientSecret: "dev-secret"}, creds)
+
+			t.Setenv(ProfileEnvVar, "prod")
+			creds, err = resolveTestCredentials(t, map[string]interface{}{})
+			require.NoError(t, err)
+			assert.Equal(t, credentials{BaseURL: "https://prod.api.openvpn.com", ClientID: "prod-id", ClientSecret: "prod-secret"}, creds)
+
+			creds, err = resolveTestCredentials(t, map[string]interface{}{"profile": "default"})
+			require.NoError(t, err)
+			assert.Equal(t, "dev-id", creds.ClientID, "the profile attribute takes precedence over the environment")
+		})
+	}
+}
+
+func TestResolveCredentials_precedence(t *testing.T) {
+	setUpCredentialsFile(t, testINICredentials)
+	t.Setenv(ClientIDEnvVar, "env-id")
+	t.Setenv(BaseURLEnvVar, "https://env.api.openvpn.com")
+
+	// The settings given outside the credentials file are never completed from the
+	// default profile, which belongs to another organization.
+	creds, err := resolveTestCredentials(t, map[string]interface{}{"base_url": "https://hcl.api.openvpn.com"})
+	require.NoError(t, err)
+	assert.Equal(t, credentials{
+		BaseURL:  "https://hcl.api.openvpn.com",
+		ClientID: "env-id",
+	}, creds)
+
+	// A selected profile supplies all three settings, whatever the environment sets.
+	t.Setenv(ClientSecretEnvVar, "env-secret")
+	for _, raw := range []map[string]interface{}{{"profile": "prod"}, {}} {
+		if len(raw) == 0 {
+			t.Setenv(ProfileEnvVar, "prod")
+		}
+		creds, err = resolveTestCredentials(t, raw)
+		require.NoError(t, err)
+		assert.Equal(t, credentials{BaseURL: "https://prod.api.openvpn.com", ClientID: "prod-id", ClientSecret: "prod-secret"}, creds)
+	}
+}
+
+func TestResolveCredentials_partialProfile(t *testing.T) {
+	setUpCredentialsFile(t, testINICredentials)
+	_, err := resolveTestCredentials(t, map[string]interface{}{"profile": "prod", "client_id": "hcl-id"})
+	assert.ErrorContains(t, err, `client_id cannot be set in the provider configuration together with the profile "prod"`)
+
+	t.Setenv(ProfileEnvVar, "prod")
+	_, err = resolveTestCredentials(t, map[string]interface{}{"client_secret": "hcl-secret"})
+	assert.ErrorContains(t, err, `client_secret cannot be set in the provider configuration together with the profile "prod" (from the CLOUDCONNEXA_PROFILE environment variable)`)
+
+	setUpCredentialsFile(t, "[staging]\nbase_url = https://staging.api.openvpn.com\nclient_id = staging-id\n")
+	_, err = resolveTestCredentials(t, map[string]interface{}{"profile": "staging"})
+	assert.ErrorContains(t, err, `profile "staging" of `)
+	assert.ErrorContains(t, err, "does not set client_secret")
+}
+
+func TestResolveCredentials_missingProfile(t *testing.T) {
+	setUpCredentialsFile(t, testINICredentials)
+	_, err := resolveTestCredentials(t, map[string]interface{}{"profile": "staging"})
+	assert.ErrorContains(t, err, `profile "staging" (from the provider configuration) not found`)
+
+	setUpCredentialsFile(t, "")
+	_, err = resolveTestCredentials(t, map[string]interface{}{"profile": "prod"})
+	assert.Error(t, err, "an explicitly selected profile must exist")
+
+	creds, err := resolveTestCredentials(t, map[string]interface{}{})
+	require.NoError(t, err, "the credentials file is optional")
+	assert.Equal(t, credentials{}, creds)
+}
+
+func TestParseINIProfiles_invalid(t *testing.T) {
+	_, err := parseINIProfiles([]byte("client_id = id\n"))
+	assert.ErrorContains(t, err, "line 1")
+
+	_, err = parseINIProfiles([]byte("[default]\nregion = eu\n"))
+	assert.ErrorContains(t, err, `unknown key "region"`)
+}
+
+func TestParseYAMLProfiles_invalid(t *testing.T) {
+	_, err := parseYAMLProfiles([]byte("default:\n  client_id: id\n  region: eu\n"))
+	assert.ErrorContains(t, err, `field region not found`)
+
+	profiles, err := parseYAMLProfiles(nil)
+	require.NoError(t, err, "an empty file has no profiles")
+	assert.Empty(t, profiles)
+}
+
+func TestCredentials_unknownSetting(t *testing.T) {
+	var creds credentials
+	assert.ErrorContains(t, creds.set("region", "eu"), `unknown credential setting "region"`)
+	_, err := creds.get("region")
+	assert.ErrorContains(t, err, `unknown credential setting "region"`)
+}
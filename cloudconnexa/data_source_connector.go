@@ -2,12 +2,9 @@ package cloudconnexa
 
 import (
 	"context"
-	"strconv"
-	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	"github.com/openvpn/cloudconnexa-go-client/v2/cloudconnexa"
 )
 
 func dataSourceConnector() *schema.Resource {
@@ -15,6 +12,11 @@ func dataSourceConnector() *schema.Resource {
 		Description: "Use an `cloudconnexa_connector` data source to read an existing Cloud Connexa connector.",
 		ReadContext: dataSourceConnectorRead,
 		Schema: map[string]*schema.Schema{
+			"connector_id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The connector ID.",
+			},
 			"name": {
 				Type:        schema.TypeString,
 				Required:    true,
@@ -50,28 +52,39 @@ func dataSourceConnector() *schema.Resource {
 				Computed:    true,
 				Description: "OpenVPN profile",
 			},
+			"connection_status": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Whether the connector is connected to Cloud Connexa, such as `online` or `offline`.",
+			},
 		},
 	}
 }
 
 func dataSourceConnectorRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*cloudconnexa.Client)
+	c := m.(*apiClient).withContext(ctx)
 	var diags diag.Diagnostics
-	connector, err := c.Connectors.GetByName(d.Get("name").(string))
+	connectorName := d.Get("name").(string)
+	connector, err := c.Connectors.GetByName(connectorName)
 	if err != nil {
-		return append(diags, diag.FromErr(err)...)
+		return append(diags, diagFromErr(ctx, err)...)
+	}
+	if connector == nil {
+		return append(diags, diag.Errorf("Connector with name %s was not found", connectorName)...)
 	}
+	d.Set("connector_id", connector.Id)
 	d.Set("name", connector.Name)
 	d.Set("network_item_id", connector.NetworkItemId)
 	d.Set("network_item_type", connector.NetworkItemType)
 	d.Set("vpn_region_id", connector.VpnRegionId)
 	d.Set("ip_v4_address", connector.IPv4Address)
 	d.Set("ip_v6_address", connector.IPv6Address)
+	d.Set("connection_status", string(connector.ConnectionStatus))
 	profile, err := c.Connectors.GetProfile(connector.Id)
 	if err != nil {
-		return append(diags, diag.FromErr(err)...)
+		return append(diags, diagFromErr(ctx, err)...)
 	}
 	d.Set("profile", profile)
-	d.SetId(strconv.FormatInt(time.Now().Unix(), 10))
+	d.SetId(connector.Id)
 	return diags
 }
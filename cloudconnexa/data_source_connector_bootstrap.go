@@ -0,0 +1,260 @@
+package cloudconnexa
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/openvpn/cloudconnexa-go-client/v2/cloudconnexa"
+	"gopkg.in/yaml.v3"
+)
+
+// systemdUnitNamePattern matches the names that can be used for the connector service
+// without quoting, in unit files as well as in shell commands.
+var systemdUnitNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_.-]+$`)
+
+// connectorForwardingSysctls are the kernel parameters that let connectors route the
+// traffic of their network.
+var connectorForwardingSysctls = []string{"net.ipv4.ip_forward", "net.ipv6.conf.all.forwarding"}
+
+// packageNamePattern matches the package names of apt and dnf.
+var packageNamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9.+_:-]*$`)
+
+func dataSourceConnectorBootstrap() *schema.Resource {
+	return &schema.Resource{
+		Description: "Use a `cloudconnexa_connector_bootstrap` data source to render the cloud-init user data that installs OpenVPN on a Linux instance and runs an existing Cloud Connexa connector as a systemd service.",
+		ReadContext: dataSourceConnectorBootstrapRead,
+		Schema: map[string]*schema.Schema{
+			"connector_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The ID of the connector whose profile is deployed.",
+			},
+			"distro_family": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "debian",
+				ValidateFunc: validation.StringInSlice([]string{"debian", "rhel"}, false),
+				Description:  "The family of the Linux distribution of the instance. `debian` covers Debian and Ubuntu, which install OpenVPN from their own repositories. `rhel` covers the Red Hat Enterprise Linux derivatives that provide the `epel-release` package, such as Rocky Linux, AlmaLinux and CentOS Stream, which install OpenVPN from EPEL. Defaults to `debian`.",
+			},
+			"packages": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.StringMatch(packageNamePattern, "must be a package name"),
+				},
+				Description: "Additional packages to install along with OpenVPN.",
+			},
+			"systemd_unit_name": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "cloudconnexa-connector",
+				ValidateFunc: validation.StringMatch(systemdUnitNamePattern, "must only contain letters, digits, `_`, `.` and `-`"),
+				Description:  "The name of the systemd service running the connector, without the `.service` suffix. The profile is written to `/etc/openvpn/client/<systemd_unit_name>.conf`. Defaults to `cloudconnexa-connector`.",
+			},
+			"ip_forwarding": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Whether IPv4 and IPv6 forwarding are enabled, which connectors need to route the traffic of their network. Defaults to `true`.",
+			},
+			"nat": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Whether the traffic leaving the instance through its default route is masqueraded, so that the hosts it reaches need no route back to Cloud Connexa. Defaults to `false`.",
+			},
+			"cloud_init": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Sensitive:   true,
+				Description: "The cloud-config document, to be used as the user data of the instance. It embeds the profile of the connector, including its private key.",
+			},
+		},
+	}
+}
+
+func dataSourceConnectorBootstrapRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*apiClient).withContext(ctx)
+	connectorId := d.Get("connector_id").(string)
+	profile, diags := readConnectorProfile(ctx, c, connectorId)
+	if diags.HasError() {
+		return diags
+	}
+	var packages []string
+	for _, p := range d.Get("packages").([]interface{}) {
+		packages = append(packages, p.(string))
+	}
+	cloudInit, err := renderConnectorCloudInit(connectorBootstrap{
+		profile:      profile,
+		distroFamily: d.Get("distro_family").(string),
+		packages:     packages,
+		unitName:     d.Get("systemd_unit_name").(string),
+		ipForwarding: d.Get("ip_forwarding").(bool),
+		nat:          d.Get("nat").(bool),
+	})
+	if err != nil {
+		return append(diags, diagFromErr(ctx, err)...)
+	}
+	d.Set("cloud_init", cloudInit)
+	d.SetId(connectorId)
+	return diags
+}
+
+// readConnectorProfile returns the profile of the connector with the given ID, which the
+// data sources deploying connectors embed in their documents.
+func readConnectorProfile(ctx context.Context, c *cloudconnexa.Client, connectorId string) (string, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	connector, err := getConnector(c, connectorId)
+	if err != nil {
+		return "", append(diags, diagFromErr(ctx, err)...)
+	}
+	if connector == nil {
+		return "", append(diags, diag.Errorf("Connector with ID %s was not found", connectorId)...)
+	}
+	profile, err := c.Connectors.GetProfile(connector.Id)
+	if err != nil {
+		return "", append(diags, diagFromErr(ctx, err)...)
+	}
+	return profile, diags
+}
+
+type connectorBootstrap struct {
+	profile      string
+	distroFamily string
+	packages     []string
+	unitName     string
+	ipForwarding bool
+	nat          bool
+}
+
+type cloudConfig struct {
+	PackageUpdate bool              `yaml:"package_update,omitempty"`
+	Packages      []string          `yaml:"packages,omitempty"`
+	WriteFiles    []cloudConfigFile `yaml:"write_files"`
+	RunCmd        [][]string        `yaml:"runcmd"`
+}
+
+type cloudConfigFile struct {
+	Path        string `yaml:"path"`
+	Owner       string `yaml:"owner"`
+	Permissions string `yaml:"permissions"`
+	Content     string `yaml:"content"`
+}
+
+const connectorUnitTemplate = `[Unit]
+Description=Cloud Connexa connector
+Wants=network-online.target
+After=network-online.target
+
+[Service]
+Type=simple
+%sExecStart=/usr/sbin/openvpn --suppress-timestamps --config /etc/openvpn/client/%s.conf
+Restart=always
+RestartSec=5
+
+[Install]
+WantedBy=multi-user.target
+`
+
+const connectorNatScript = `#!/bin/sh
+# Masquerades the traffic leaving the instance through its default routes.
+set -e
+iface=$(ip -4 route show default | awk '{print $5; exit}')
+if [ -n "$iface" ]; then
+  iptables -t nat -C POSTROUTING -o "$iface" -j MASQUERADE 2>/dev/null || iptables -t nat -A POSTROUTING -o "$iface" -j MASQUERADE
+fi
+# IPv6 NAT is not available on every kernel, so it must not prevent the connector from
+# starting.
+iface6=$(ip -6 route show default | awk '{print $5; exit}')
+if [ -n "$iface6" ]; then
+  ip6tables -t nat -C POSTROUTING -o "$iface6" -j MASQUERADE 2>/dev/null || ip6tables -t nat -A POSTROUTING -o "$iface6" -j MASQUERADE || true
+fi
+`
+
+// renderConnectorCloudInit returns a cloud-config document that installs OpenVPN,
+// writes the profile of the connector and starts it as a systemd service on boot.
+func renderConnectorCloudInit(b connectorBootstrap) (string, error) {
+	packages := append([]string{"openvpn"}, b.packages...)
+	if b.nat {
+		packages = append(packages, "iptables")
+	}
+	unit := b.unitName + ".service"
+
+	config := cloudConfig{}
+	switch b.distroFamily {
+	case "debian":
+		config.PackageUpdate = true
+		config.Packages = packages
+	case "rhel":
+		// OpenVPN is not in the base repositories, so EPEL must be enabled before the
+		// packages are installed.
+		config.RunCmd = append(config.RunCmd,
+			[]string{"dnf", "install", "-y", "epel-release"},
+			append([]string{"dnf", "install", "-y"}, packages...),
+		)
+	default:
+		return "", fmt.Errorf("unsupported distro family %q", b.distroFamily)
+	}
+
+	config.WriteFiles = append(config.WriteFiles, cloudConfigFile{
+		Path:        fmt.Sprintf("/etc/openvpn/client/%s.conf", b.unitName),
+		Owner:       "root:root",
+		Permissions: "0600",
+		Content:     withTrailingNewline(b.profile),
+	})
+	var execStartPre string
+	if b.nat {
+		natScript := fmt.Sprintf("/usr/local/sbin/%s-nat", b.unitName)
+		config.WriteFiles = append(config.WriteFiles, cloudConfigFile{
+			Path:        natScript,
+			Owner:       "root:root",
+			Permissions: "0755",
+			Content:     connectorNatScript,
+		})
+		execStartPre = fmt.Sprintf("ExecStartPre=%s\n", natScript)
+	}
+	config.WriteFiles = append(config.WriteFiles, cloudConfigFile{
+		Path:        "/etc/systemd/system/" + unit,
+		Owner:       "root:root",
+		Permissions: "0644",
+		Content:     fmt.Sprintf(connectorUnitTemplate, execStartPre, b.unitName),
+	})
+	if b.ipForwarding {
+		var sysctls strings.Builder
+		for _, name := range connectorForwardingSysctls {
+			fmt.Fprintf(&sysctls, "%s = 1\n", name)
+		}
+		config.WriteFiles = append(config.WriteFiles, cloudConfigFile{
+			Path:        fmt.Sprintf("/etc/sysctl.d/90-%s.conf", b.unitName),
+			Owner:       "root:root",
+			Permissions: "0644",
+			Content:     sysctls.String(),
+		})
+		config.RunCmd = append(config.RunCmd, []string{"sysctl", "--system"})
+	}
+	config.RunCmd = append(config.RunCmd,
+		[]string{"systemctl", "daemon-reload"},
+		[]string{"systemctl", "enable", "--now", unit},
+	)
+
+	document, err := yaml.Marshal(config)
+	if err != nil {
+		return "", err
+	}
+	return "#cloud-config\n" + string(document), nil
+}
+
+// withTrailingNewline makes the profile end with a newline, so that it is written as a
+// literal block in YAML documents.
+func withTrailingNewline(profile string) string {
+	if strings.HasSuffix(profile, "\n") {
+		return profile
+	}
+	return profile + "\n"
+}
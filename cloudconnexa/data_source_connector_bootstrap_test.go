@@ -0,0 +1,76 @@
+package cloudconnexa
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/OpenVPN/terraform-provider-openvpn-cloud/internal/fakeapi"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/openvpn/cloudconnexa-go-client/v2/cloudconnexa"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestDataSourceConnectorBootstrap(t *testing.T) {
+	server := fakeapi.NewServer()
+	defer server.Close()
+	p := newTestProvider(t, server)
+	meta := p.Meta().(*apiClient)
+	network, err := meta.client.Networks.Create(cloudconnexa.Network{
+		Name:       "network",
+		Connectors: []cloudconnexa.NetworkConnector{{Name: "connector", VpnRegionId: "fi-hel"}},
+	})
+	require.NoError(t, err)
+	connectorId := network.Connectors[0].Id
+	profile, err := meta.client.Connectors.GetProfile(connectorId)
+	require.NoError(t, err)
+
+	r := p.DataSourcesMap["cloudconnexa_connector_bootstrap"]
+	for _, tc := range []struct {
+		golden string
+		raw    map[string]interface{}
+		unit   string
+	}{
+		{"connector_bootstrap_debian.yaml", map[string]interface{}{}, "cloudconnexa-connector"},
+		{"connector_bootstrap_rhel_nat.yaml", map[string]interface{}{
+			"distro_family":     "rhel",
+			"packages":          []interface{}{"tcpdump", "bind-utils"},
+			"systemd_unit_name": "connector-fi",
+			"ip_forwarding":     false,
+			"nat":               true,
+		}, "connector-fi"},
+	} {
+		tc.raw["connector_id"] = connectorId
+		d := schema.TestResourceDataRaw(t, r.Schema, tc.raw)
+		diags := r.ReadContext(context.Background(), d, meta)
+		require.False(t, diags.HasError(), "%v", diags)
+		assert.Equal(t, connectorId, d.Id())
+
+		cloudInit := d.Get("cloud_init").(string)
+		assertGolden(t, tc.golden, cloudInit)
+		require.True(t, strings.HasPrefix(cloudInit, "#cloud-config\n"), tc.golden)
+		var config cloudConfig
+		require.NoError(t, yaml.Unmarshal([]byte(cloudInit), &config), tc.golden)
+		files := map[string]string{}
+		for _, f := range config.WriteFiles {
+			files[f.Path] = f.Content
+		}
+		assert.Equal(t, profile, files["/etc/openvpn/client/"+tc.unit+".conf"], "%s must embed the profile", tc.golden)
+		assert.Contains(t, files["/etc/systemd/system/"+tc.unit+".service"], "--config /etc/openvpn/client/"+tc.unit+".conf")
+		assert.Equal(t, []string{"systemctl", "enable", "--now", tc.unit + ".service"}, config.RunCmd[len(config.RunCmd)-1])
+	}
+}
+
+func TestDataSourceConnectorBootstrap_unknownConnector(t *testing.T) {
+	server := fakeapi.NewServer()
+	defer server.Close()
+	p := newTestProvider(t, server)
+	r := p.DataSourcesMap["cloudconnexa_connector_bootstrap"]
+	d := r.TestResourceData()
+	require.NoError(t, d.Set("connector_id", "missing"))
+	diags := r.ReadContext(context.Background(), d, p.Meta())
+	require.True(t, diags.HasError())
+	assert.Equal(t, "Connector with ID missing was not found", diags[0].Summary)
+}
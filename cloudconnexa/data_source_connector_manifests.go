@@ -0,0 +1,341 @@
+package cloudconnexa
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"gopkg.in/yaml.v3"
+)
+
+// kubernetesNamePattern matches the DNS labels that Kubernetes accepts as the names of
+// objects and namespaces.
+var kubernetesNamePattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+// connectorProfileFile is the name of the profile in the directory it is mounted on.
+const connectorProfileFile = "connector.conf"
+
+const connectorProfileDir = "/etc/openvpn/client"
+
+func dataSourceConnectorManifests() *schema.Resource {
+	return &schema.Resource{
+		Description: "Use a `cloudconnexa_connector_manifests` data source to render the Kubernetes manifests and the Docker Compose file that run an existing Cloud Connexa connector in a container.",
+		ReadContext: dataSourceConnectorManifestsRead,
+		Schema: map[string]*schema.Schema{
+			"connector_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The ID of the connector whose profile is deployed.",
+			},
+			"image": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.StringIsNotWhiteSpace,
+				Description:  "The container image running the connector. It must provide the `openvpn` binary on its `PATH`.",
+			},
+			"name": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "cloudconnexa-connector",
+				ValidateFunc: validation.All(
+					validation.StringLenBetween(1, 55),
+					validation.StringMatch(kubernetesNamePattern, "must only contain lowercase letters, digits and `-`, and start and end with a letter or a digit"),
+				),
+				Description: "The name of the Kubernetes Deployment and of the Docker Compose service. The Secret holding the profile is named `<name>-profile`. Defaults to `cloudconnexa-connector`.",
+			},
+			"namespace": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringMatch(kubernetesNamePattern, "must be a Kubernetes namespace"),
+				Description:  "The namespace of the Kubernetes objects. When not set, the manifests are applied to the namespace of the current context.",
+			},
+			"ip_forwarding": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+				Description: "Whether IPv4 and IPv6 forwarding are enabled in the network namespace of the container, which connectors need to route the traffic of their network. " +
+					"Kubernetes treats these sysctls as unsafe, so the kubelet of the nodes must allow them with `--allowed-unsafe-sysctls`. Defaults to `true`.",
+			},
+			"kubernetes_manifest": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Sensitive:   true,
+				Description: "The Secret holding the profile of the connector and the Deployment running it, as a multi-document YAML manifest. The Deployment runs a single replica and is recreated on changes, as a connector only accepts one connection at a time.",
+			},
+			"docker_compose": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Sensitive:   true,
+				Description: "The Docker Compose file running the connector as a service. The profile is embedded as an inline config, which requires Docker Compose 2.23.1 or later.",
+			},
+		},
+	}
+}
+
+func dataSourceConnectorManifestsRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*apiClient).withContext(ctx)
+	connectorId := d.Get("connector_id").(string)
+	profile, diags := readConnectorProfile(ctx, c, connectorId)
+	if diags.HasError() {
+		return diags
+	}
+	deployment := connectorDeployment{
+		profile:      profile,
+		image:        d.Get("image").(string),
+		name:         d.Get("name").(string),
+		namespace:    d.Get("namespace").(string),
+		ipForwarding: d.Get("ip_forwarding").(bool),
+	}
+	kubernetesManifest, err := renderConnectorKubernetesManifest(deployment)
+	if err != nil {
+		return append(diags, diagFromErr(ctx, err)...)
+	}
+	dockerCompose, err := renderConnectorDockerCompose(deployment)
+	if err != nil {
+		return append(diags, diagFromErr(ctx, err)...)
+	}
+	d.Set("kubernetes_manifest", kubernetesManifest)
+	d.Set("docker_compose", dockerCompose)
+	d.SetId(connectorId)
+	return diags
+}
+
+type connectorDeployment struct {
+	profile      string
+	image        string
+	name         string
+	namespace    string
+	ipForwarding bool
+}
+
+// fileMode is a file mode written in octal, as in the manifests written by hand. Both
+// Kubernetes and Docker Compose read numbers with a leading zero as octal.
+type fileMode uint32
+
+func (m fileMode) MarshalYAML() (interface{}, error) {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: fmt.Sprintf("0%o", m)}, nil
+}
+
+func (d connectorDeployment) command() []string {
+	return []string{"openvpn", "--suppress-timestamps", "--config", connectorProfileDir + "/" + connectorProfileFile}
+}
+
+func (d connectorDeployment) profileName() string {
+	return d.name + "-profile"
+}
+
+type kubernetesMetadata struct {
+	Name      string            `yaml:"name,omitempty"`
+	Namespace string            `yaml:"namespace,omitempty"`
+	Labels    map[string]string `yaml:"labels,omitempty"`
+}
+
+type kubernetesSecret struct {
+	APIVersion string             `yaml:"apiVersion"`
+	Kind       string             `yaml:"kind"`
+	Metadata   kubernetesMetadata `yaml:"metadata"`
+	Type       string             `yaml:"type"`
+	StringData map[string]string  `yaml:"stringData"`
+}
+
+type kubernetesDeployment struct {
+	APIVersion string             `yaml:"apiVersion"`
+	Kind       string             `yaml:"kind"`
+	Metadata   kubernetesMetadata `yaml:"metadata"`
+	Spec       struct {
+		Replicas int `yaml:"replicas"`
+		Strategy struct {
+			Type string `yaml:"type"`
+		} `yaml:"strategy"`
+		Selector struct {
+			MatchLabels map[string]string `yaml:"matchLabels"`
+		} `yaml:"selector"`
+		Template struct {
+			Metadata kubernetesMetadata `yaml:"metadata"`
+			Spec     kubernetesPodSpec  `yaml:"spec"`
+		} `yaml:"template"`
+	} `yaml:"spec"`
+}
+
+type kubernetesPodSpec struct {
+	SecurityContext *kubernetesPodSecurityContext `yaml:"securityContext,omitempty"`
+	Containers      []kubernetesContainer         `yaml:"containers"`
+	Volumes         []kubernetesVolume            `yaml:"volumes"`
+}
+
+type kubernetesPodSecurityContext struct {
+	Sysctls []kubernetesSysctl `yaml:"sysctls"`
+}
+
+type kubernetesSysctl struct {
+	Name  string `yaml:"name"`
+	Value string `yaml:"value"`
+}
+
+type kubernetesContainer struct {
+	Name            string   `yaml:"name"`
+	Image           string   `yaml:"image"`
+	Command         []string `yaml:"command"`
+	SecurityContext struct {
+		Capabilities struct {
+			Add []string `yaml:"add"`
+		} `yaml:"capabilities"`
+	} `yaml:"securityContext"`
+	VolumeMounts []kubernetesVolumeMount `yaml:"volumeMounts"`
+}
+
+type kubernetesVolumeMount struct {
+	Name      string `yaml:"name"`
+	MountPath string `yaml:"mountPath"`
+	ReadOnly  bool   `yaml:"readOnly,omitempty"`
+}
+
+type kubernetesVolume struct {
+	Name     string                          `yaml:"name"`
+	Secret   *kubernetesSecretVolumeSource   `yaml:"secret,omitempty"`
+	HostPath *kubernetesHostPathVolumeSource `yaml:"hostPath,omitempty"`
+}
+
+type kubernetesSecretVolumeSource struct {
+	SecretName  string   `yaml:"secretName"`
+	DefaultMode fileMode `yaml:"defaultMode"`
+}
+
+type kubernetesHostPathVolumeSource struct {
+	Path string `yaml:"path"`
+	Type string `yaml:"type"`
+}
+
+// renderConnectorKubernetesManifest returns a Secret holding the profile of the connector
+// and a Deployment running it with access to the TUN device of the node.
+func renderConnectorKubernetesManifest(d connectorDeployment) (string, error) {
+	labels := map[string]string{"app.kubernetes.io/name": d.name}
+
+	secret := kubernetesSecret{
+		APIVersion: "v1",
+		Kind:       "Secret",
+		Metadata:   kubernetesMetadata{Name: d.profileName(), Namespace: d.namespace, Labels: labels},
+		Type:       "Opaque",
+		StringData: map[string]string{connectorProfileFile: withTrailingNewline(d.profile)},
+	}
+
+	deployment := kubernetesDeployment{
+		APIVersion: "apps/v1",
+		Kind:       "Deployment",
+		Metadata:   kubernetesMetadata{Name: d.name, Namespace: d.namespace, Labels: labels},
+	}
+	// Two pods using the same profile would take the connection from each other, so the
+	// old pod must be gone before the new one starts.
+	deployment.Spec.Replicas = 1
+	deployment.Spec.Strategy.Type = "Recreate"
+	deployment.Spec.Selector.MatchLabels = labels
+	deployment.Spec.Template.Metadata = kubernetesMetadata{Labels: labels}
+
+	pod := &deployment.Spec.Template.Spec
+	if d.ipForwarding {
+		pod.SecurityContext = &kubernetesPodSecurityContext{}
+		for _, name := range connectorForwardingSysctls {
+			pod.SecurityContext.Sysctls = append(pod.SecurityContext.Sysctls, kubernetesSysctl{Name: name, Value: "1"})
+		}
+	}
+	container := kubernetesContainer{
+		Name:    "connector",
+		Image:   d.image,
+		Command: d.command(),
+		VolumeMounts: []kubernetesVolumeMount{
+			{Name: "profile", MountPath: connectorProfileDir, ReadOnly: true},
+			{Name: "tun", MountPath: "/dev/net/tun"},
+		},
+	}
+	container.SecurityContext.Capabilities.Add = []string{"NET_ADMIN"}
+	pod.Containers = []kubernetesContainer{container}
+
+	pod.Volumes = []kubernetesVolume{
+		{Name: "profile", Secret: &kubernetesSecretVolumeSource{SecretName: d.profileName(), DefaultMode: 0o400}},
+		{Name: "tun", HostPath: &kubernetesHostPathVolumeSource{Path: "/dev/net/tun", Type: "CharDevice"}},
+	}
+
+	var manifest bytes.Buffer
+	encoder := yaml.NewEncoder(&manifest)
+	encoder.SetIndent(2)
+	for _, object := range []interface{}{secret, deployment} {
+		if err := encoder.Encode(object); err != nil {
+			return "", err
+		}
+	}
+	if err := encoder.Close(); err != nil {
+		return "", err
+	}
+	return manifest.String(), nil
+}
+
+type composeFile struct {
+	Services map[string]composeService `yaml:"services"`
+	Configs  map[string]composeConfig  `yaml:"configs"`
+}
+
+type composeService struct {
+	Image   string              `yaml:"image"`
+	Command []string            `yaml:"command"`
+	Restart string              `yaml:"restart"`
+	CapAdd  []string            `yaml:"cap_add"`
+	Devices []string            `yaml:"devices"`
+	Sysctls map[string]string   `yaml:"sysctls,omitempty"`
+	Configs []composeConfigFile `yaml:"configs"`
+}
+
+type composeConfigFile struct {
+	Source string   `yaml:"source"`
+	Target string   `yaml:"target"`
+	Mode   fileMode `yaml:"mode"`
+}
+
+type composeConfig struct {
+	Content string `yaml:"content"`
+}
+
+// renderConnectorDockerCompose returns a Docker Compose file running the connector with
+// the same settings as the Kubernetes Deployment.
+func renderConnectorDockerCompose(d connectorDeployment) (string, error) {
+	service := composeService{
+		Image:   d.image,
+		Command: d.command(),
+		Restart: "unless-stopped",
+		CapAdd:  []string{"NET_ADMIN"},
+		Devices: []string{"/dev/net/tun:/dev/net/tun"},
+		Configs: []composeConfigFile{{
+			Source: d.profileName(),
+			Target: connectorProfileDir + "/" + connectorProfileFile,
+			Mode:   0o400,
+		}},
+	}
+	if d.ipForwarding {
+		service.Sysctls = map[string]string{}
+		for _, name := range connectorForwardingSysctls {
+			service.Sysctls[name] = "1"
+		}
+	}
+	file := composeFile{
+		Services: map[string]composeService{d.name: service},
+		Configs: map[string]composeConfig{d.profileName(): {
+			// Docker Compose interpolates variables in every value of the file.
+			Content: strings.ReplaceAll(withTrailingNewline(d.profile), "$", "$$"),
+		}},
+	}
+
+	var compose bytes.Buffer
+	encoder := yaml.NewEncoder(&compose)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(file); err != nil {
+		return "", err
+	}
+	if err := encoder.Close(); err != nil {
+		return "", err
+	}
+	return compose.String(), nil
+}
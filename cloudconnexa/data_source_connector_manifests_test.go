@@ -0,0 +1,100 @@
+package cloudconnexa
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/OpenVPN/terraform-provider-openvpn-cloud/internal/fakeapi"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/openvpn/cloudconnexa-go-client/v2/cloudconnexa"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestDataSourceConnectorManifests(t *testing.T) {
+	server := fakeapi.NewServer()
+	defer server.Close()
+	p := newTestProvider(t, server)
+	meta := p.Meta().(*apiClient)
+	network, err := meta.client.Networks.Create(cloudconnexa.Network{
+		Name:       "network",
+		Connectors: []cloudconnexa.NetworkConnector{{Name: "connector", VpnRegionId: "fi-hel"}},
+	})
+	require.NoError(t, err)
+	connectorId := network.Connectors[0].Id
+	profile, err := meta.client.Connectors.GetProfile(connectorId)
+	require.NoError(t, err)
+
+	r := p.DataSourcesMap["cloudconnexa_connector_manifests"]
+	for _, tc := range []struct {
+		golden string
+		raw    map[string]interface{}
+		name   string
+	}{
+		{"connector_manifests_default", map[string]interface{}{}, "cloudconnexa-connector"},
+		{"connector_manifests_no_forwarding", map[string]interface{}{
+			"name":          "connector-fi",
+			"namespace":     "vpn",
+			"ip_forwarding": false,
+		}, "connector-fi"},
+	} {
+		tc.raw["connector_id"] = connectorId
+		tc.raw["image"] = "registry.example.com/openvpn:2.6"
+		d := schema.TestResourceDataRaw(t, r.Schema, tc.raw)
+		diags := r.ReadContext(context.Background(), d, meta)
+		require.False(t, diags.HasError(), "%v", diags)
+		assert.Equal(t, connectorId, d.Id())
+
+		manifest := d.Get("kubernetes_manifest").(string)
+		assertGolden(t, tc.golden+".k8s.yaml", manifest)
+		var objects []map[string]interface{}
+		decoder := yaml.NewDecoder(bytes.NewBufferString(manifest))
+		for {
+			var object map[string]interface{}
+			err := decoder.Decode(&object)
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			require.NoError(t, err, tc.golden)
+			objects = append(objects, object)
+		}
+		require.Len(t, objects, 2, tc.golden)
+		assert.Equal(t, "Secret", objects[0]["kind"])
+		assert.Equal(t, profile, objects[0]["stringData"].(map[string]interface{})["connector.conf"], "%s must embed the profile", tc.golden)
+		assert.Equal(t, "Deployment", objects[1]["kind"])
+		assert.Equal(t, tc.name, objects[1]["metadata"].(map[string]interface{})["name"])
+
+		compose := d.Get("docker_compose").(string)
+		assertGolden(t, tc.golden+".compose.yaml", compose)
+		var file composeFile
+		require.NoError(t, yaml.Unmarshal([]byte(compose), &file), tc.golden)
+		require.Contains(t, file.Services, tc.name)
+		assert.Equal(t, []string{"NET_ADMIN"}, file.Services[tc.name].CapAdd)
+		assert.Equal(t, profile, file.Configs[tc.name+"-profile"].Content, "%s must embed the profile", tc.golden)
+	}
+}
+
+func TestRenderConnectorDockerCompose_escapesVariables(t *testing.T) {
+	compose, err := renderConnectorDockerCompose(connectorDeployment{
+		profile: "setenv UV_NAME $HOSTNAME",
+		image:   "openvpn",
+		name:    "connector",
+	})
+	require.NoError(t, err)
+	assert.Contains(t, compose, "setenv UV_NAME $$HOSTNAME\n")
+}
+
+func TestDataSourceConnectorManifests_unknownConnector(t *testing.T) {
+	server := fakeapi.NewServer()
+	defer server.Close()
+	p := newTestProvider(t, server)
+	r := p.DataSourcesMap["cloudconnexa_connector_manifests"]
+	d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{"connector_id": "missing", "image": "openvpn"})
+	diags := r.ReadContext(context.Background(), d, p.Meta())
+	require.True(t, diags.HasError())
+	assert.Equal(t, "Connector with ID missing was not found", diags[0].Summary)
+}
@@ -2,12 +2,9 @@ package cloudconnexa
 
 import (
 	"context"
-	"strconv"
-	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	"github.com/openvpn/cloudconnexa-go-client/v2/cloudconnexa"
 )
 
 func dataSourceHost() *schema.Resource {
@@ -15,6 +12,11 @@ func dataSourceHost() *schema.Resource {
 		Description: "Use an `cloudconnexa_host` data source to read an existing Cloud Connexa connector.",
 		ReadContext: dataSourceHostRead,
 		Schema: map[string]*schema.Schema{
+			"host_id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The host ID.",
+			},
 			"name": {
 				Type:        schema.TypeString,
 				Required:    true,
@@ -74,6 +76,11 @@ func dataSourceHost() *schema.Resource {
 							Computed:    true,
 							Description: "The IPV6 address of the connector.",
 						},
+						"connection_status": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Whether the connector is connected to Cloud Connexa, such as `online` or `offline`.",
+						},
 					},
 				},
 			},
@@ -82,16 +89,21 @@ func dataSourceHost() *schema.Resource {
 }
 
 func dataSourceHostRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*cloudconnexa.Client)
+	c := m.(*apiClient).withContext(ctx)
 	var diags diag.Diagnostics
-	host, err := c.Hosts.GetByName(d.Get("name").(string))
+	hostName := d.Get("name").(string)
+	host, err := c.Hosts.GetByName(hostName)
 	if err != nil {
-		return append(diags, diag.FromErr(err)...)
+		return append(diags, diagFromErr(ctx, err)...)
+	}
+	if host == nil {
+		return append(diags, diag.Errorf("Host with name %s was not found", hostName)...)
 	}
+	d.Set("host_id", host.Id)
 	d.Set("name", host.Name)
 	d.Set("internet_access", host.InternetAccess)
 	d.Set("system_subnets", host.SystemSubnets)
 	d.Set("connectors", getConnectorsSlice(&host.Connectors))
-	d.SetId(strconv.FormatInt(time.Now().Unix(), 10))
+	d.SetId(host.Id)
 	return diags
 }
@@ -4,7 +4,6 @@ import (
 	"context"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	"github.com/openvpn/cloudconnexa-go-client/v2/cloudconnexa"
 )
 
 func dataSourceIPService() *schema.Resource {
@@ -52,13 +51,10 @@ func dataSourceIPService() *schema.Resource {
 }
 
 func dataSourceIPServiceRead(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
-	c := i.(*cloudconnexa.Client)
-	service, err := c.IPServices.Get(
-		data.Id(),
-	)
-
+	c := i.(*apiClient).withContext(ctx)
+	service, err := c.IPServices.Get(data.Get("id").(string))
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromErr(ctx, err)
 	}
 	setResourceData(data, service)
 	return nil
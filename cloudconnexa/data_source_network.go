@@ -3,8 +3,6 @@ package cloudconnexa
 import (
 	"context"
 	"github.com/openvpn/cloudconnexa-go-client/v2/cloudconnexa"
-	"strconv"
-	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -116,12 +114,12 @@ func dataSourceNetwork() *schema.Resource {
 }
 
 func dataSourceNetworkRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*cloudconnexa.Client)
+	c := m.(*apiClient).withContext(ctx)
 	var diags diag.Diagnostics
 	networkName := d.Get("name").(string)
 	network, err := c.Networks.GetByName(networkName)
 	if err != nil {
-		return append(diags, diag.FromErr(err)...)
+		return append(diags, diagFromErr(ctx, err)...)
 	}
 	if network == nil {
 		return append(diags, diag.Errorf("Network with name %s was not found", networkName)...)
@@ -134,7 +132,7 @@ func dataSourceNetworkRead(ctx context.Context, d *schema.ResourceData, m interf
 	d.Set("system_subnets", network.SystemSubnets)
 	d.Set("routes", getRoutesSlice(&network.Routes))
 	//d.Set("connectors", getConnectorsSlice(&network.Connectors))
-	d.SetId(strconv.FormatInt(time.Now().Unix(), 10))
+	d.SetId(network.Id)
 	return diags
 }
 
@@ -161,6 +159,7 @@ func getConnectorsSlice(connectors *[]cloudconnexa.Connector) []interface{} {
 		connector["vpn_region_id"] = c.VpnRegionId
 		connector["ip_v4_address"] = c.IPv4Address
 		connector["ip_v6_address"] = c.IPv6Address
+		connector["connection_status"] = string(c.ConnectionStatus)
 		conns[i] = connector
 	}
 	return conns
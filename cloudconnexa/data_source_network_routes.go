@@ -2,9 +2,6 @@ package cloudconnexa
 
 import (
 	"context"
-	"github.com/openvpn/cloudconnexa-go-client/v2/cloudconnexa"
-	"strconv"
-	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -34,12 +31,17 @@ func dataSourceNetworkRoutes() *schema.Resource {
 						"type": {
 							Type:        schema.TypeString,
 							Computed:    true,
-							Description: "The type of route. Valid values are `IP_V4`, `IP_V6`, and others.",
+							Description: "The type of route. Valid values are `IP_V4`, `IP_V6`, and `DOMAIN`.",
 						},
 						"subnet": {
 							Type:        schema.TypeString,
 							Computed:    true,
-							Description: "The subnet of the route.",
+							Description: "The subnet of the route. Empty for `DOMAIN` routes.",
+						},
+						"domain": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The domain of the route, such as `example.com` or `*.example.com`. Only set for `DOMAIN` routes.",
 						},
 						"description": {
 							Type:        schema.TypeString,
@@ -54,13 +56,13 @@ func dataSourceNetworkRoutes() *schema.Resource {
 }
 
 func dataSourceNetworkRoutesRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*cloudconnexa.Client)
+	c := m.(*apiClient).withContext(ctx)
 	var diags diag.Diagnostics
 
 	networkId := d.Get("network_item_id").(string)
-	routes, err := c.Routes.List(networkId)
+	routes, err := listRoutes(c, networkId)
 	if err != nil {
-		return append(diags, diag.FromErr(err)...)
+		return append(diags, diagFromErr(ctx, err)...)
 	}
 
 	configRoutes := make([]map[string]interface{}, len(routes))
@@ -69,14 +71,15 @@ func dataSourceNetworkRoutesRead(ctx context.Context, d *schema.ResourceData, m
 		route["id"] = r.Id
 		route["type"] = r.Type
 		route["subnet"] = r.Subnet
+		route["domain"] = r.Domain
 		route["description"] = r.Description
 		configRoutes[i] = route
 	}
 
 	if err := d.Set("routes", configRoutes); err != nil {
-		return append(diags, diag.FromErr(err)...)
+		return append(diags, diagFromErr(ctx, err)...)
 	}
 
-	d.SetId(strconv.FormatInt(time.Now().Unix(), 10))
+	d.SetId(networkId)
 	return diags
 }
@@ -0,0 +1,64 @@
+package cloudconnexa
+
+import (
+	"context"
+	"testing"
+
+	"github.com/OpenVPN/terraform-provider-openvpn-cloud/internal/fakeapi"
+	"github.com/openvpn/cloudconnexa-go-client/v2/cloudconnexa"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataSources_useObjectIds(t *testing.T) {
+	server := fakeapi.NewServer()
+	defer server.Close()
+	p := newTestProvider(t, server)
+	meta := p.Meta().(*apiClient)
+	c := meta.client
+
+	network, err := c.Networks.Create(cloudconnexa.Network{
+		Name:       "network",
+		Connectors: []cloudconnexa.NetworkConnector{{Name: "connector", VpnRegionId: "fi-hel"}},
+	})
+	require.NoError(t, err)
+	host, err := c.Hosts.Create(cloudconnexa.Host{
+		Name:       "host",
+		Connectors: []cloudconnexa.Connector{{Name: "host-connector", VpnRegionId: "fi-hel"}},
+	})
+	require.NoError(t, err)
+	group, err := c.UserGroups.Create(&cloudconnexa.UserGroup{Name: "group", VpnRegionIds: []string{"fi-hel"}})
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		dataSource string
+		raw        map[string]interface{}
+		id         string
+		idAttr     string
+	}{
+		{"cloudconnexa_network", map[string]interface{}{"name": "network"}, network.Id, "network_id"},
+		{"cloudconnexa_connector", map[string]interface{}{"name": "connector"}, network.Connectors[0].Id, "connector_id"},
+		{"cloudconnexa_host", map[string]interface{}{"name": "host"}, host.Id, "host_id"},
+		{"cloudconnexa_user_group", map[string]interface{}{"name": "group"}, group.ID, "user_group_id"},
+		{"cloudconnexa_vpn_region", map[string]interface{}{"region_id": "fi-hel"}, "fi-hel", "region_id"},
+		{"cloudconnexa_network_routes", map[string]interface{}{"network_item_id": network.Id}, network.Id, "network_item_id"},
+	} {
+		r := p.DataSourcesMap[tc.dataSource]
+		for i := 0; i < 2; i++ {
+			d := r.TestResourceData()
+			for k, v := range tc.raw {
+				require.NoError(t, d.Set(k, v))
+			}
+			diags := r.ReadContext(context.Background(), d, meta)
+			require.False(t, diags.HasError(), "%s: %v", tc.dataSource, diags)
+			assert.Equal(t, tc.id, d.Id(), tc.dataSource)
+			assert.Equal(t, tc.id, d.Get(tc.idAttr), tc.dataSource)
+		}
+	}
+
+	r := p.DataSourcesMap["cloudconnexa_host"]
+	d := r.TestResourceData()
+	require.NoError(t, d.Set("name", "missing"))
+	diags := r.ReadContext(context.Background(), d, meta)
+	assert.True(t, diags.HasError(), "an unknown host must be reported rather than panic")
+}
@@ -3,8 +3,6 @@ package cloudconnexa
 import (
 	"context"
 	"github.com/openvpn/cloudconnexa-go-client/v2/cloudconnexa"
-	"strconv"
-	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -99,12 +97,12 @@ func dataSourceUser() *schema.Resource {
 }
 
 func dataSourceUserRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*cloudconnexa.Client)
+	c := m.(*apiClient).withContext(ctx)
 	var diags diag.Diagnostics
 	userName := d.Get("username").(string)
 	user, err := c.Users.Get(userName)
 	if err != nil {
-		return append(diags, diag.FromErr(err)...)
+		return append(diags, diagFromErr(ctx, err)...)
 	}
 	if user == nil {
 		return append(diags, diag.Errorf("User with name %s was not found", userName)...)
@@ -120,7 +118,7 @@ func dataSourceUserRead(ctx context.Context, d *schema.ResourceData, m interface
 	d.Set("group_id", user.GroupId)
 	d.Set("status", user.Status)
 	d.Set("devices", getUserDevicesSlice(&user.Devices))
-	d.SetId(strconv.FormatInt(time.Now().Unix(), 10))
+	d.SetId(user.Id)
 	return diags
 }
 
@@ -2,9 +2,6 @@ package cloudconnexa
 
 import (
 	"context"
-	"github.com/openvpn/cloudconnexa-go-client/v2/cloudconnexa"
-	"strconv"
-	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -56,12 +53,12 @@ func dataSourceUserGroup() *schema.Resource {
 }
 
 func dataSourceUserGroupRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*cloudconnexa.Client)
+	c := m.(*apiClient).withContext(ctx)
 	var diags diag.Diagnostics
 	userGroupName := d.Get("name").(string)
 	userGroup, err := c.UserGroups.GetByName(userGroupName)
 	if err != nil {
-		return append(diags, diag.FromErr(err)...)
+		return append(diags, diagFromErr(ctx, err)...)
 	}
 	if userGroup == nil {
 		return append(diags, diag.Errorf("User group with name %s was not found", userGroupName)...)
@@ -72,6 +69,6 @@ func dataSourceUserGroupRead(ctx context.Context, d *schema.ResourceData, m inte
 	d.Set("internet_access", userGroup.InternetAccess)
 	d.Set("max_device", userGroup.MaxDevice)
 	d.Set("system_subnets", userGroup.SystemSubnets)
-	d.SetId(strconv.FormatInt(time.Now().Unix(), 10))
+	d.SetId(userGroup.ID)
 	return diags
 }
@@ -2,9 +2,6 @@ package cloudconnexa
 
 import (
 	"context"
-	"github.com/openvpn/cloudconnexa-go-client/v2/cloudconnexa"
-	"strconv"
-	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -45,12 +42,12 @@ func dataSourceVpnRegion() *schema.Resource {
 }
 
 func dataSourceVpnRegionRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*cloudconnexa.Client)
+	c := m.(*apiClient).withContext(ctx)
 	var diags diag.Diagnostics
 	vpnRegionId := d.Get("region_id").(string)
 	vpnRegion, err := c.VPNRegions.GetVpnRegion(vpnRegionId)
 	if err != nil {
-		return append(diags, diag.FromErr(err)...)
+		return append(diags, diagFromErr(ctx, err)...)
 	}
 	if vpnRegion == nil {
 		return append(diags, diag.Errorf("VPN region with id %s was not found", vpnRegionId)...)
@@ -60,6 +57,6 @@ func dataSourceVpnRegionRead(ctx context.Context, d *schema.ResourceData, m inte
 	d.Set("country", vpnRegion.Country)
 	d.Set("country_iso", vpnRegion.CountryISO)
 	d.Set("region_name", vpnRegion.RegionName)
-	d.SetId(strconv.FormatInt(time.Now().Unix(), 10))
+	d.SetId(vpnRegion.Id)
 	return diags
 }
@@ -0,0 +1,59 @@
+package cloudconnexa
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// deletionProtectionSchema returns the `deletion_protection` attribute of the resources
+// whose deletion disrupts the traffic of the organization.
+func deletionProtectionSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeBool,
+		Optional: true,
+		Default:  false,
+		Description: "Whether Terraform refuses to delete this resource, including when a change requires replacing it. " +
+			"Must be set to `false` and applied before the resource can be destroyed. Defaults to `false`.",
+	}
+}
+
+// protectFromDeletion wraps the Delete function of every resource that has a
+// `deletion_protection` attribute so that it fails before calling the API while the
+// attribute is true. Terraform deletes replaced resources with the same function and
+// their prior state, so replacements are refused as well.
+func protectFromDeletion(resources map[string]*schema.Resource) {
+	for name, r := range resources {
+		if _, ok := r.Schema["deletion_protection"]; !ok {
+			continue
+		}
+		r.DeleteContext = refuseWhenProtected(name, r.DeleteContext)
+		if r.Importer != nil && r.Importer.StateContext != nil {
+			importState := r.Importer.StateContext
+			r.Importer.StateContext = func(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+				// The attribute only exists in Terraform, so record its default rather than
+				// plan to set it after the import.
+				d.Set("deletion_protection", false)
+				return importState(ctx, d, m)
+			}
+		}
+	}
+}
+
+func refuseWhenProtected(resourceType string, f resourceFunc) resourceFunc {
+	return func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+		if d.Get("deletion_protection").(bool) {
+			return diag.Diagnostics{{
+				Severity: diag.Error,
+				Summary:  "Deletion protection is enabled",
+				Detail: fmt.Sprintf("%s %s cannot be deleted or replaced because deletion_protection is true. "+
+					"Set deletion_protection = false and apply that change first.", resourceType, d.Id()),
+				AttributePath: cty.GetAttrPath("deletion_protection"),
+			}}
+		}
+		return f(ctx, d, m)
+	}
+}
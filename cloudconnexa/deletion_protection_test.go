@@ -0,0 +1,92 @@
+package cloudconnexa
+
+import (
+	"context"
+	"testing"
+
+	"github.com/OpenVPN/terraform-provider-openvpn-cloud/internal/fakeapi"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeletionProtection_refusesDelete(t *testing.T) {
+	// The client is nil, so any function that gets past the guard panics.
+	meta := &apiClient{}
+	resources := Provider().ResourcesMap
+
+	for _, name := range []string{"cloudconnexa_network", "cloudconnexa_host", "cloudconnexa_user_group", "cloudconnexa_connector"} {
+		r := resources[name]
+		d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{"deletion_protection": true})
+		d.SetId("id")
+		diags := r.DeleteContext(context.Background(), d, meta)
+		require.True(t, diags.HasError(), name)
+		assert.Equal(t, "Deletion protection is enabled", diags[0].Summary)
+		assert.Contains(t, diags[0].Detail, name+" id cannot be deleted")
+	}
+}
+
+func TestDeletionProtection_refusesReplacement(t *testing.T) {
+	server := fakeapi.NewServer()
+	defer server.Close()
+	p := newTestProvider(t, server)
+	meta := p.Meta().(*apiClient)
+	network := func(name, subnet string) *terraform.InstanceState {
+		return testApply(t, p.ResourcesMap["cloudconnexa_network"], nil, map[string]interface{}{
+			"name":          name,
+			"connector":     []interface{}{map[string]interface{}{"name": "west", "vpn_region_id": "us-west-1"}},
+			"default_route": []interface{}{map[string]interface{}{"subnet": subnet}},
+		}, meta)
+	}
+	prod, staging := network("prod", "10.1.0.0/16"), network("staging", "10.2.0.0/16")
+
+	r := p.ResourcesMap["cloudconnexa_connector"]
+	config := func(networkItemId string, protected bool) map[string]interface{} {
+		return map[string]interface{}{
+			"name":                "connector",
+			"vpn_region_id":       "us-west-1",
+			"network_item_type":   "NETWORK",
+			"network_item_id":     networkItemId,
+			"deletion_protection": protected,
+		}
+	}
+	state := testApply(t, r, nil, config(prod.ID, true), meta)
+
+	diff, err := r.Diff(context.Background(), state, terraform.NewResourceConfigRaw(config(staging.ID, true)), meta)
+	require.NoError(t, err)
+	require.True(t, diff.RequiresNew())
+	_, diags := r.Apply(context.Background(), state, diff, meta)
+	require.True(t, diags.HasError())
+	assert.Equal(t, "Deletion protection is enabled", diags[0].Summary)
+	connector, err := meta.client.Connectors.GetByID(state.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, connector, "the protected connector must not be deleted")
+
+	// Once the protection is lifted, the connector can be replaced.
+	state = testApply(t, r, state, config(prod.ID, false), meta)
+	assert.Equal(t, connector.Id, state.ID)
+	state = testApply(t, r, state, config(staging.ID, false), meta)
+	assert.NotEqual(t, connector.Id, state.ID)
+}
+
+func TestDeletionProtection_importRecordsDefault(t *testing.T) {
+	server := fakeapi.NewServer()
+	defer server.Close()
+	p := newTestProvider(t, server)
+	meta := p.Meta().(*apiClient)
+	r := p.ResourcesMap["cloudconnexa_host"]
+	config := map[string]interface{}{
+		"name":      "host",
+		"connector": []interface{}{map[string]interface{}{"name": "west", "vpn_region_id": "us-west-1"}},
+	}
+	created := testApply(t, r, nil, config, meta)
+
+	imported, err := r.Importer.StateContext(context.Background(), r.Data(&terraform.InstanceState{ID: created.ID}), meta)
+	require.NoError(t, err)
+	state, diags := r.RefreshWithoutUpgrade(context.Background(), imported[0].State(), meta)
+	require.False(t, diags.HasError(), "%v", diags)
+	diff, err := r.Diff(context.Background(), state, terraform.NewResourceConfigRaw(config), meta)
+	require.NoError(t, err)
+	assert.Nil(t, diff)
+}
@@ -0,0 +1,368 @@
+package cloudconnexa
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// diagFromErr converts an error returned by the Cloud Connexa client into diagnostics.
+// When the context of the operation has ended, the transport error is replaced by a
+// message that explains the timeout or cancellation.
+func diagFromErr(ctx context.Context, err error) diag.Diagnostics {
+	if err == nil {
+		return nil
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		if errors.Is(ctxErr, context.DeadlineExceeded) {
+			return diag.Diagnostics{{
+				Severity: diag.Error,
+				Summary:  "Timed out waiting for the Cloud Connexa API",
+				Detail:   fmt.Sprintf("The operation did not complete within the configured timeout. Increase the corresponding value in the `timeouts` block of the resource and try again.\n\nError: %v", err),
+			}}
+		}
+		return diag.Diagnostics{{
+			Severity: diag.Error,
+			Summary:  "Cloud Connexa API request canceled",
+			Detail:   fmt.Sprintf("The operation was canceled before it completed.\n\nError: %v", err),
+		}}
+	}
+	if apiErr, ok := parseApiError(err); ok {
+		return apiErr.diagnostics()
+	}
+	return diag.FromErr(err)
+}
+
+// notFoundPattern matches the errors the client library returns itself when an object
+// it looked up is not in the listing.
+var notFoundPattern = regexp.MustCompile(`^[\w ]+ \S+ (not found|does not exist)$`)
+
+// isNotFound reports whether err means that the object does not exist.
+func isNotFound(err error) bool {
+	if err == nil {
+		return false
+	}
+	if apiErr, ok := parseApiError(err); ok {
+		return apiErr.StatusCode == http.StatusNotFound
+	}
+	return notFoundPattern.MatchString(err.Error())
+}
+
+// removeFromState removes the resource from the state when its object no longer
+// exists, so that Terraform plans to recreate it rather than failing.
+func removeFromState(d *schema.ResourceData, kind string) diag.Diagnostics {
+	id := d.Id()
+	d.SetId("")
+	return diag.Diagnostics{{
+		Severity: diag.Warning,
+		Summary:  fmt.Sprintf("The %s no longer exists", kind),
+		Detail: fmt.Sprintf("The %s %s was not found in Cloud Connexa, most likely because it was deleted outside "+
+			"Terraform. It has been removed from the state and will be created again on the next apply.", kind, id),
+	}}
+}
+
+// apiErrorPattern matches the errors the client library returns for unsuccessful
+// responses.
+var apiErrorPattern = regexp.MustCompile(`(?s)status code: (\d+), response body: (.*)$`)
+
+// apiError is an error response of the Cloud Connexa API.
+type apiError struct {
+	StatusCode  int
+	Code        string
+	Message     string
+	FieldErrors []apiFieldError
+}
+
+type apiFieldError struct {
+	// Field is the API name of the offending field, e.g. `connectors[0].vpnRegionId`.
+	Field   string
+	Message string
+}
+
+// apiErrorBody is the error response of the Cloud Connexa API. fieldErrors is only
+// sent for validation errors.
+type apiErrorBody struct {
+	ErrorCode    string `json:"errorCode"`
+	ErrorMessage string `json:"errorMessage"`
+	FieldErrors  []struct {
+		Field   string `json:"field"`
+		Message string `json:"message"`
+	} `json:"fieldErrors"`
+}
+
+// parseApiError extracts the status and error body from an error of the client
+// library. A body that is not an API error response is kept as the message.
+func parseApiError(err error) (*apiError, bool) {
+	match := apiErrorPattern.FindStringSubmatch(err.Error())
+	if match == nil {
+		return nil, false
+	}
+	statusCode, _ := strconv.Atoi(match[1])
+	apiErr := &apiError{StatusCode: statusCode}
+	body := strings.TrimSpace(match[2])
+
+	var response apiErrorBody
+	if json.Unmarshal([]byte(body), &response) != nil || (response.ErrorCode == "" && response.ErrorMessage == "") {
+		if len(body) > 500 {
+			body = body[:500] + "..."
+		}
+		apiErr.Message = body
+		return apiErr, true
+	}
+	apiErr.Code = response.ErrorCode
+	apiErr.Message = response.ErrorMessage
+	for _, f := range response.FieldErrors {
+		apiErr.FieldErrors = append(apiErr.FieldErrors, apiFieldError{Field: f.Field, Message: f.Message})
+	}
+	return apiErr, true
+}
+
+func (e *apiError) summary() string {
+	code := strings.ToUpper(e.Code)
+	switch {
+	case strings.Contains(code, "DUPLICATE") || strings.Contains(code, "ALREADY_EXISTS") || e.StatusCode == http.StatusConflict:
+		return "Object already exists in Cloud Connexa"
+	case strings.Contains(code, "QUOTA") || strings.Contains(code, "LIMIT"):
+		return "Cloud Connexa quota exceeded"
+	case strings.Contains(code, "REGION"):
+		return "Invalid VPN region"
+	case e.StatusCode == http.StatusUnauthorized:
+		return "Cloud Connexa API authentication failed"
+	case e.StatusCode == http.StatusForbidden:
+		return "Permission denied by the Cloud Connexa API"
+	case e.StatusCode == http.StatusNotFound:
+		return "Object not found in Cloud Connexa"
+	case e.StatusCode == http.StatusTooManyRequests:
+		return "Cloud Connexa API rate limit exceeded"
+	case e.StatusCode >= 500:
+		return "Cloud Connexa API error"
+	case len(e.FieldErrors) > 0:
+		return "Invalid value rejected by the Cloud Connexa API"
+	}
+	return "Cloud Connexa API rejected the request"
+}
+
+// impliedField returns the field an error code refers to when the API does not name
+// it, in the snake case of the schema.
+func (e *apiError) impliedField() string {
+	code := strings.ToUpper(e.Code)
+	switch {
+	case strings.Contains(code, "USERNAME"):
+		return "username"
+	case strings.Contains(code, "DOMAIN"):
+		return "domain"
+	case strings.Contains(code, "NAME"):
+		return "name"
+	case strings.Contains(code, "REGION"):
+		return "vpn_region_id"
+	}
+	return ""
+}
+
+func (e *apiError) diagnostics() diag.Diagnostics {
+	summary := e.summary()
+	status := fmt.Sprintf("HTTP status %d", e.StatusCode)
+	if e.Code != "" {
+		status = fmt.Sprintf("error code %s, %s", e.Code, status)
+	}
+	if len(e.FieldErrors) == 0 {
+		message := e.Message
+		if message == "" {
+			message = http.StatusText(e.StatusCode)
+		}
+		return diag.Diagnostics{{
+			Severity:      diag.Error,
+			Summary:       summary,
+			Detail:        fmt.Sprintf("%s\n\n(%s)", message, status),
+			AttributePath: apiFieldPath(e.impliedField()),
+		}}
+	}
+	var diags diag.Diagnostics
+	for _, fieldErr := range e.FieldErrors {
+		detail := fieldErr.Message
+		if fieldErr.Field != "" {
+			detail = fmt.Sprintf("%s: %s", fieldErr.Field, fieldErr.Message)
+		}
+		if e.Message != "" {
+			detail = fmt.Sprintf("%s\n\n%s", e.Message, detail)
+		}
+		diags = append(diags, diag.Diagnostic{
+			Severity:      diag.Error,
+			Summary:       summary,
+			Detail:        fmt.Sprintf("%s\n\n(%s)", detail, status),
+			AttributePath: apiFieldPath(fieldErr.Field),
+		})
+	}
+	return diags
+}
+
+var (
+	fieldSegmentPattern = regexp.MustCompile(`^([A-Za-z0-9_]+)((?:\[\d+\])*)$`)
+	fieldIndexPattern   = regexp.MustCompile(`\d+`)
+)
+
+// apiFieldPath converts an API field name such as `connectors[0].vpnRegionId` into
+// the corresponding attribute path, here `connectors.0.vpn_region_id`. The path may
+// not exist in the schema; attachAttributePaths resolves it per resource.
+func apiFieldPath(field string) cty.Path {
+	if field == "" {
+		return nil
+	}
+	var path cty.Path
+	for _, segment := range strings.Split(field, ".") {
+		match := fieldSegmentPattern.FindStringSubmatch(segment)
+		if match == nil {
+			return nil
+		}
+		path = path.GetAttr(camelToSnake(match[1]))
+		for _, index := range fieldIndexPattern.FindAllString(match[2], -1) {
+			i, _ := strconv.Atoi(index)
+			path = path.IndexInt(i)
+		}
+	}
+	return path
+}
+
+func camelToSnake(s string) string {
+	var b strings.Builder
+	runes := []rune(s)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && !unicode.IsUpper(runes[i-1]) {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// attributeAliases maps the first attribute of an API field path to the attributes of
+// the resources that configure the same field under a different name.
+var attributeAliases = map[string][]string{
+	"connectors":    {"connector", "default_connector"},
+	"routes":        {"default_route"},
+	"vpn_region_id": {"vpn_region_ids", "default_connector.0.vpn_region_id", "connector"},
+	"name":          {"username", "domain"},
+}
+
+// attachAttributePaths makes the diagnostics of every resource and data source point
+// at an attribute of its schema, or at no attribute when the field of an API error
+// has no counterpart in it.
+func attachAttributePaths(resourceMaps ...map[string]*schema.Resource) {
+	for _, resources := range resourceMaps {
+		for _, r := range resources {
+			if r.CreateContext != nil {
+				r.CreateContext = resolveAttributePaths(r.Schema, r.CreateContext)
+			}
+			if r.ReadContext != nil {
+				r.ReadContext = resolveAttributePaths(r.Schema, r.ReadContext)
+			}
+			if r.UpdateContext != nil {
+				r.UpdateContext = resolveAttributePaths(r.Schema, r.UpdateContext)
+			}
+			if r.DeleteContext != nil {
+				r.DeleteContext = resolveAttributePaths(r.Schema, r.DeleteContext)
+			}
+		}
+	}
+}
+
+func resolveAttributePaths(schemaMap map[string]*schema.Schema, f resourceFunc) resourceFunc {
+	return func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+		diags := f(ctx, d, m)
+		for i := range diags {
+			if len(diags[i].AttributePath) > 0 {
+				diags[i].AttributePath = resolveAttributePath(schemaMap, diags[i].AttributePath, func(name string) bool {
+					_, ok := d.GetOk(name)
+					return ok
+				})
+			}
+		}
+		return diags
+	}
+}
+
+// resolveAttributePath returns the path of schemaMap that path or one of its aliases
+// refers to, preferring the attributes that isSet reports as set when several match.
+func resolveAttributePath(schemaMap map[string]*schema.Schema, path cty.Path, isSet func(name string) bool) cty.Path {
+	if resolved, ok := schemaPath(schemaMap, path); ok {
+		return resolved
+	}
+	first, ok := path[0].(cty.GetAttrStep)
+	if !ok {
+		return nil
+	}
+	var candidates []cty.Path
+	for _, alias := range attributeAliases[first.Name] {
+		var aliasPath cty.Path
+		for _, step := range strings.Split(alias, ".") {
+			if i, err := strconv.Atoi(step); err == nil {
+				aliasPath = aliasPath.IndexInt(i)
+			} else {
+				aliasPath = aliasPath.GetAttr(step)
+			}
+		}
+		if resolved, ok := schemaPath(schemaMap, append(aliasPath, path[1:]...)); ok {
+			candidates = append(candidates, resolved)
+		}
+	}
+	for _, candidate := range candidates {
+		if isSet(candidate[0].(cty.GetAttrStep).Name) {
+			return candidate
+		}
+	}
+	if len(candidates) > 0 {
+		return candidates[0]
+	}
+	return nil
+}
+
+// schemaPath reports whether path exists in schemaMap. Paths into a set are cut at
+// the set, whose elements cannot be addressed by index.
+func schemaPath(schemaMap map[string]*schema.Schema, path cty.Path) (cty.Path, bool) {
+	for i := 0; i < len(path); i++ {
+		step, ok := path[i].(cty.GetAttrStep)
+		if !ok {
+			return nil, false
+		}
+		s, ok := schemaMap[step.Name]
+		if !ok {
+			return nil, false
+		}
+		if i == len(path)-1 {
+			return path, true
+		}
+		elem, isResource := s.Elem.(*schema.Resource)
+		switch {
+		case s.Type == schema.TypeSet:
+			return path[:i+1], true
+		case s.Type == schema.TypeList && isResource:
+			if _, ok := path[i+1].(cty.IndexStep); !ok {
+				return nil, false
+			}
+			if i+1 == len(path)-1 {
+				return path, true
+			}
+			schemaMap = elem.Schema
+			i++
+		case s.Type == schema.TypeList || s.Type == schema.TypeMap:
+			// Elements of primitive lists and maps are addressed by the whole attribute.
+			return path[:i+1], true
+		default:
+			return nil, false
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,212 @@
+package cloudconnexa
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/OpenVPN/terraform-provider-openvpn-cloud/internal/fakeapi"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/openvpn/cloudconnexa-go-client/v2/cloudconnexa"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseApiError(t *testing.T) {
+	apiErr, ok := parseApiError(errors.New(`status code: 400, response body: {"errorCode":"VALIDATION_ERROR","errorMessage":"invalid request",` +
+		`"fieldErrors":[{"field":"connectors[0].vpnRegionId","message":"unknown region"},{"field":"name","message":"too long"}]}`))
+	require.True(t, ok)
+	assert.Equal(t, &apiError{
+		StatusCode: 400,
+		Code:       "VALIDATION_ERROR",
+		Message:    "invalid request",
+		FieldErrors: []apiFieldError{
+			{Field: "connectors[0].vpnRegionId", Message: "unknown region"},
+			{Field: "name", Message: "too long"},
+		},
+	}, apiErr)
+
+	// Bodies of another shape are not guessed at.
+	apiErr, ok = parseApiError(errors.New(`status code: 400, response body: {"message":"invalid request","errors":["name is too long"]}`))
+	require.True(t, ok)
+	assert.Equal(t, &apiError{StatusCode: 400, Message: `{"message":"invalid request","errors":["name is too long"]}`}, apiErr)
+
+	apiErr, ok = parseApiError(errors.New("status code: 502, response body: <html>Bad Gateway</html>"))
+	require.True(t, ok)
+	assert.Equal(t, &apiError{StatusCode: 502, Message: "<html>Bad Gateway</html>"}, apiErr)
+
+	_, ok = parseApiError(errors.New("network not found"))
+	assert.False(t, ok)
+}
+
+func TestApiFieldPath(t *testing.T) {
+	assert.Equal(t, cty.GetAttrPath("connectors").IndexInt(0).GetAttr("vpn_region_id"), apiFieldPath("connectors[0].vpnRegionId"))
+	assert.Equal(t, cty.GetAttrPath("ip_v4_address"), apiFieldPath("ipV4Address"))
+	assert.Equal(t, cty.GetAttrPath("network_item_id"), apiFieldPath("networkItemId"))
+	assert.Nil(t, apiFieldPath(""))
+	assert.Nil(t, apiFieldPath("connectors[*]"))
+}
+
+func TestIsNotFound(t *testing.T) {
+	assert.True(t, isNotFound(errors.New(`status code: 404, response body: {"errorCode":"NOT_FOUND"}`)))
+	assert.True(t, isNotFound(errors.New("DNS record with ID 1234 not found")))
+	assert.True(t, isNotFound(errors.New("group 1234 does not exist")))
+	assert.False(t, isNotFound(errors.New(`status code: 400, response body: {"errorMessage":"network 1234 does not exist"}`)))
+	assert.False(t, isNotFound(errors.New("dial tcp: connection refused")))
+	assert.False(t, isNotFound(nil))
+}
+
+func TestDiagFromErr_apiErrors(t *testing.T) {
+	ctx := context.Background()
+
+	diags := diagFromErr(ctx, errors.New(`status code: 409, response body: {"errorCode":"DUPLICATE_USERNAME","errorMessage":"user \"jdoe\" already exists"}`))
+	require.Len(t, diags, 1)
+	assert.Equal(t, "Object already exists in Cloud Connexa", diags[0].Summary)
+	assert.Equal(t, "user \"jdoe\" already exists\n\n(error code DUPLICATE_USERNAME, HTTP status 409)", diags[0].Detail)
+	assert.Equal(t, cty.GetAttrPath("username"), diags[0].AttributePath)
+
+	diags = diagFromErr(ctx, errors.New(`status code: 400, response body: {"errorCode":"QUOTA_EXCEEDED","errorMessage":"too many networks"}`))
+	require.Len(t, diags, 1)
+	assert.Equal(t, "Cloud Connexa quota exceeded", diags[0].Summary)
+	assert.Nil(t, diags[0].AttributePath)
+
+	diags = diagFromErr(ctx, errors.New("status code: 503, response body: "))
+	require.Len(t, diags, 1)
+	assert.Equal(t, "Cloud Connexa API error", diags[0].Summary)
+	assert.Contains(t, diags[0].Detail, "Service Unavailable")
+}
+
+func TestResolveAttributePath(t *testing.T) {
+	resources := Provider().ResourcesMap
+	for _, tc := range []struct {
+		resource string
+		path     cty.Path
+		expected cty.Path
+	}{
+		{"cloudconnexa_network", cty.GetAttrPath("name"), cty.GetAttrPath("name")},
+		{"cloudconnexa_network", apiFieldPath("connectors[0].vpnRegionId"), cty.GetAttrPath("connector")},
+		{"cloudconnexa_network", apiFieldPath("vpnRegionId"), cty.GetAttrPath("default_connector").IndexInt(0).GetAttr("vpn_region_id")},
+		{"cloudconnexa_host", apiFieldPath("connectors[1].vpnRegionId"), cty.GetAttrPath("connector")},
+		{"cloudconnexa_connector", apiFieldPath("vpnRegionId"), cty.GetAttrPath("vpn_region_id")},
+		{"cloudconnexa_user_group", apiFieldPath("vpnRegionIds[2]"), cty.GetAttrPath("vpn_region_ids")},
+		{"cloudconnexa_user_group", apiFieldPath("vpnRegionId"), cty.GetAttrPath("vpn_region_ids")},
+		{"cloudconnexa_user", apiFieldPath("name"), cty.GetAttrPath("username")},
+		{"cloudconnexa_network", apiFieldPath("unknownField"), nil},
+	} {
+		assert.Equal(t, tc.expected, resolveAttributePath(resources[tc.resource].Schema, tc.path, func(string) bool { return false }), "%s %v", tc.resource, tc.path)
+	}
+
+	isSet := func(name string) bool { return name == "default_connector" }
+	assert.Equal(t, cty.GetAttrPath("default_connector").IndexInt(0).GetAttr("vpn_region_id"),
+		resolveAttributePath(resources["cloudconnexa_network"].Schema, apiFieldPath("connectors[0].vpnRegionId"), isSet),
+		"the attribute used in the configuration must be preferred")
+}
+
+func TestDiagFromErr_pointsAtConfiguredAttribute(t *testing.T) {
+	server := fakeapi.NewServer()
+	defer server.Close()
+	server.NetworkLimit = 1
+	p := newTestProvider(t, server)
+	r := p.ResourcesMap["cloudconnexa_network"]
+	create := func(name, region string) diag.Diagnostics {
+		d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+			"name": name,
+			"default_connector": []interface{}{map[string]interface{}{
+				"name":          "connector",
+				"vpn_region_id": region,
+			}},
+			"default_route": []interface{}{map[string]interface{}{
+				"type":   "IP_V4",
+				"subnet": "10.0.0.0/16",
+			}},
+		})
+		return r.CreateContext(context.Background(), d, p.Meta())
+	}
+
+	diags := create("network", "xx-nowhere-1")
+	require.Len(t, diags, 1)
+	assert.Equal(t, "Invalid VPN region", diags[0].Summary)
+	assert.Contains(t, diags[0].Detail, `unknown VPN region "xx-nowhere-1"`)
+	assert.Equal(t, cty.GetAttrPath("default_connector").IndexInt(0).GetAttr("vpn_region_id"), diags[0].AttributePath)
+
+	require.False(t, create("network", "fi-hel").HasError())
+	diags = create("network", "fi-hel")
+	require.Len(t, diags, 1)
+	assert.Equal(t, "Object already exists in Cloud Connexa", diags[0].Summary)
+	assert.Equal(t, cty.GetAttrPath("name"), diags[0].AttributePath)
+
+	diags = create("other", "fi-hel")
+	require.Len(t, diags, 1)
+	assert.Equal(t, "Cloud Connexa quota exceeded", diags[0].Summary)
+	assert.Contains(t, diags[0].Detail, "QUOTA_EXCEEDED")
+}
+
+func TestRead_removesObjectsDeletedOutsideTerraform(t *testing.T) {
+	server := fakeapi.NewServer()
+	defer server.Close()
+	p := newTestProvider(t, server)
+	meta := p.Meta().(*apiClient)
+	c := meta.client
+
+	network, err := c.Networks.Create(cloudconnexa.Network{
+		Name:       "network",
+		Connectors: []cloudconnexa.NetworkConnector{{Name: "connector", VpnRegionId: "fi-hel"}},
+	})
+	require.NoError(t, err)
+	route, err := c.Routes.Create(network.Id, cloudconnexa.Route{Type: "IP_V4", Subnet: "10.0.0.0/16"})
+	require.NoError(t, err)
+	service, err := c.IPServices.Create(&cloudconnexa.IPService{
+		Name:            "service",
+		Type:            "IP_SOURCE",
+		NetworkItemType: "NETWORK",
+		NetworkItemId:   network.Id,
+		Routes:          []*cloudconnexa.IPServiceRoute{{Value: "10.0.0.1/32"}},
+	})
+	require.NoError(t, err)
+	host, err := c.Hosts.Create(cloudconnexa.Host{
+		Name:       "host",
+		Connectors: []cloudconnexa.Connector{{Name: "host-connector", VpnRegionId: "fi-hel"}},
+	})
+	require.NoError(t, err)
+	record, err := c.DnsRecords.Create(cloudconnexa.DnsRecord{Domain: "app.example.com", IPV4Addresses: []string{"10.0.0.1"}})
+	require.NoError(t, err)
+	user, err := c.Users.Create(cloudconnexa.User{Username: "jdoe", Email: "jdoe@example.com"})
+	require.NoError(t, err)
+	group, err := c.UserGroups.Create(&cloudconnexa.UserGroup{Name: "group", VpnRegionIds: []string{"fi-hel"}})
+	require.NoError(t, err)
+
+	objects := []struct {
+		resource string
+		id       string
+		delete   func() error
+	}{
+		{"cloudconnexa_route", route.Id, func() error { return c.Routes.Delete(network.Id, route.Id) }},
+		{"cloudconnexa_ip_service", service.Id, func() error { return c.IPServices.Delete(service.Id) }},
+		{"cloudconnexa_connector", network.Connectors[0].Id, func() error {
+			return c.Connectors.Delete(network.Connectors[0].Id, network.Id, "NETWORK")
+		}},
+		{"cloudconnexa_network", network.Id, func() error { return c.Networks.Delete(network.Id) }},
+		{"cloudconnexa_host", host.Id, func() error { return c.Hosts.Delete(host.Id) }},
+		{"cloudconnexa_dns_record", record.Id, func() error { return c.DnsRecords.Delete(record.Id) }},
+		{"cloudconnexa_user", user.Id, func() error { return c.Users.Delete(user.Id) }},
+		{"cloudconnexa_user_group", group.ID, func() error { return c.UserGroups.Delete(group.ID) }},
+	}
+	for _, o := range objects {
+		r := p.ResourcesMap[o.resource]
+		d := r.TestResourceData()
+		d.SetId(o.id)
+		diags := r.ReadContext(context.Background(), d, meta)
+		require.False(t, diags.HasError(), "%s: %v", o.resource, diags)
+		require.Equal(t, o.id, d.Id(), o.resource)
+
+		require.NoError(t, o.delete(), o.resource)
+		diags = r.ReadContext(context.Background(), d, meta)
+		require.False(t, diags.HasError(), "%s: %v", o.resource, diags)
+		assert.Empty(t, d.Id(), "%s must be removed from the state", o.resource)
+		require.Len(t, diags, 1, o.resource)
+		assert.Equal(t, diag.Warning, diags[0].Severity, o.resource)
+		assert.Contains(t, diags[0].Detail, o.id, o.resource)
+	}
+}
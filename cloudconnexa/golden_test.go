@@ -0,0 +1,31 @@
+package cloudconnexa
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// UpdateGoldenEnvVar makes the tests that compare generated documents with the files in
+// testdata/golden rewrite those files instead.
+const UpdateGoldenEnvVar = "CLOUDCONNEXA_UPDATE_GOLDEN"
+
+const goldenDir = "testdata/golden"
+
+// assertGolden checks that got matches testdata/golden/<name>, or writes it there when
+// CLOUDCONNEXA_UPDATE_GOLDEN is set.
+func assertGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join(goldenDir, name)
+	if os.Getenv(UpdateGoldenEnvVar) != "" {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(got), 0o644))
+		return
+	}
+	want, err := os.ReadFile(path)
+	require.NoError(t, err, "run the tests with %s=1 to create the golden file", UpdateGoldenEnvVar)
+	assert.Equal(t, string(want), got, "run the tests with %s=1 to update the golden file", UpdateGoldenEnvVar)
+}
@@ -0,0 +1,188 @@
+package cloudconnexa
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const redacted = "REDACTED"
+
+var (
+	sensitiveKeys = map[string]bool{
+		"access_token":  true,
+		"refresh_token": true,
+		"id_token":      true,
+		"client_secret": true,
+		"password":      true,
+		"token":         true,
+	}
+	// Connector profiles embed the private key and TLS keys of the connector.
+	inlineKeyPattern  = regexp.MustCompile(`(?s)(<(key|tls-crypt|tls-crypt-v2|tls-auth)>).*?(</(key|tls-crypt|tls-crypt-v2|tls-auth)>)`)
+	privateKeyPattern = regexp.MustCompile(`(?s)-----BEGIN ([A-Z ]*)PRIVATE KEY-----.*?-----END ([A-Z ]*)PRIVATE KEY-----`)
+	profilePath       = regexp.MustCompile(`/connectors/[^/]+/profile$`)
+	requestIdHeaders  = []string{"X-Request-Id", "X-Amzn-Requestid", "X-Correlation-Id"}
+)
+
+// redactBody removes tokens, secrets and keys from a request or response body.
+func redactBody(body string) string {
+	var v interface{}
+	if body != "" && json.Unmarshal([]byte(body), &v) == nil {
+		if redactJSON(v) {
+			if data, err := json.Marshal(v); err == nil {
+				body = string(data)
+			}
+		}
+	}
+	body = inlineKeyPattern.ReplaceAllString(body, "${1}\n"+redacted+"\n${3}")
+	return privateKeyPattern.ReplaceAllString(body, redacted)
+}
+
+func redactJSON(v interface{}) bool {
+	changed := false
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if _, ok := value.(string); ok && sensitiveKeys[strings.ToLower(key)] {
+				v[key] = redacted
+				changed = true
+				continue
+			}
+			changed = redactJSON(value) || changed
+		}
+	case []interface{}:
+		for _, value := range v {
+			changed = redactJSON(value) || changed
+		}
+	}
+	return changed
+}
+
+type logSubsystemKey struct{}
+
+// withLogSubsystem makes the API requests issued with ctx log to the subsystem of
+// the resource type, whose level can be set with TF_LOG_PROVIDER_<RESOURCE_TYPE>.
+func withLogSubsystem(ctx context.Context, resourceType string) context.Context {
+	ctx = tflog.NewSubsystem(ctx, resourceType, tflog.WithLevelFromEnv("TF_LOG_PROVIDER", resourceType), tflog.WithRootFields())
+	return context.WithValue(ctx, logSubsystemKey{}, resourceType)
+}
+
+func logDebug(ctx context.Context, msg string, fields map[string]interface{}) {
+	if subsystem, ok := ctx.Value(logSubsystemKey{}).(string); ok {
+		tflog.SubsystemDebug(ctx, subsystem, msg, fields)
+		return
+	}
+	tflog.Debug(ctx, msg, fields)
+}
+
+func logTrace(ctx context.Context, msg string, fields map[string]interface{}) {
+	if subsystem, ok := ctx.Value(logSubsystemKey{}).(string); ok {
+		tflog.SubsystemTrace(ctx, subsystem, msg, fields)
+		return
+	}
+	tflog.Trace(ctx, msg, fields)
+}
+
+// logWithSubsystems makes every function of the resources and data sources log its
+// API requests to the subsystem named after its type.
+func logWithSubsystems(resourceMaps ...map[string]*schema.Resource) {
+	for _, resources := range resourceMaps {
+		for name, r := range resources {
+			if r.CreateContext != nil {
+				r.CreateContext = logToSubsystem(name, r.CreateContext)
+			}
+			if r.ReadContext != nil {
+				r.ReadContext = logToSubsystem(name, r.ReadContext)
+			}
+			if r.UpdateContext != nil {
+				r.UpdateContext = logToSubsystem(name, r.UpdateContext)
+			}
+			if r.DeleteContext != nil {
+				r.DeleteContext = logToSubsystem(name, r.DeleteContext)
+			}
+			if r.Importer != nil && r.Importer.StateContext != nil {
+				importState := r.Importer.StateContext
+				r.Importer.StateContext = func(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+					return importState(withLogSubsystem(ctx, name), d, m)
+				}
+			}
+		}
+	}
+}
+
+func logToSubsystem(resourceType string, f resourceFunc) resourceFunc {
+	return func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+		return f(withLogSubsystem(ctx, resourceType), d, m)
+	}
+}
+
+// loggingTransport logs every request sent to the API with its status and latency
+// at DEBUG level, and the redacted request and response bodies at TRACE level.
+type loggingTransport struct {
+	next http.RoundTripper
+}
+
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	fields := map[string]interface{}{
+		"http_method": req.Method,
+		"http_path":   req.URL.Path,
+	}
+	if req.URL.RawQuery != "" {
+		fields["http_query"] = req.URL.RawQuery
+	}
+	if req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			data, _ := io.ReadAll(body)
+			body.Close()
+			logTrace(ctx, "Cloud Connexa API request body", withField(fields, "http_request_body", redactBody(string(data))))
+		}
+	}
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	fields["latency_ms"] = time.Since(start).Milliseconds()
+	if err != nil {
+		logDebug(ctx, "Cloud Connexa API request failed", withField(fields, "error", err.Error()))
+		return nil, err
+	}
+	fields["http_status"] = resp.StatusCode
+	for _, header := range requestIdHeaders {
+		if id := resp.Header.Get(header); id != "" {
+			fields["request_id"] = id
+			break
+		}
+	}
+	logDebug(ctx, "Cloud Connexa API request", fields)
+
+	data, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+	body := redactBody(string(data))
+	if profilePath.MatchString(req.URL.Path) && resp.StatusCode < 300 {
+		body = redacted
+	}
+	logTrace(ctx, "Cloud Connexa API response body", withField(fields, "http_response_body", body))
+	return resp, nil
+}
+
+func withField(fields map[string]interface{}, key string, value interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(fields)+1)
+	for k, v := range fields {
+		copied[k] = v
+	}
+	copied[key] = value
+	return copied
+}
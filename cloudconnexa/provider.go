@@ -3,77 +3,235 @@ package cloudconnexa
 import (
 	"context"
 	"fmt"
-	"github.com/openvpn/cloudconnexa-go-client/v2/cloudconnexa"
+	"net/http"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 const (
 	ClientIDEnvVar     = "CLOUDCONNEXA_CLIENT_ID"
 	ClientSecretEnvVar = "CLOUDCONNEXA_CLIENT_SECRET"
+	BaseURLEnvVar      = "CLOUDCONNEXA_BASE_URL"
+	ProfileEnvVar      = "CLOUDCONNEXA_PROFILE"
+	ReadOnlyEnvVar     = "CLOUDCONNEXA_READ_ONLY"
 )
 
+// defaultTimeout applies to every operation of a resource unless overridden in its
+// `timeouts` block.
+const defaultTimeout = 10 * time.Minute
+
 type Token struct {
 	AccessToken string `json:"access_token"`
 }
 
 func Provider() *schema.Provider {
-	return &schema.Provider{
+	p := &schema.Provider{
 		Schema: map[string]*schema.Schema{
 			"client_id": {
 				Description: "The authentication client_id used to connect to Cloud Connexa API. The value can be sourced from " +
-					"the `CLOUDCONNEXA_CLIENT_ID` environment variable.",
-				Type:        schema.TypeString,
-				Optional:    true,
-				Sensitive:   true,
-				DefaultFunc: schema.EnvDefaultFunc(ClientIDEnvVar, nil),
+					"the `CLOUDCONNEXA_CLIENT_ID` environment variable or the credentials profile.",
+				Type:      schema.TypeString,
+				Optional:  true,
+				Sensitive: true,
 			},
 			"client_secret": {
 				Description: "The authentication client_secret used to connect to Cloud Connexa API. The value can be sourced from " +
-					"the `CLOUDCONNEXA_CLIENT_SECRET` environment variable.",
+					"the `CLOUDCONNEXA_CLIENT_SECRET` environment variable or the credentials profile.",
+				Type:      schema.TypeString,
+				Optional:  true,
+				Sensitive: true,
+			},
+			"base_url": {
+				Description: "The target Cloud Connexa Base API URL in the format `https://[companyName].api.openvpn.com`. The value can be sourced from " +
+					"the `CLOUDCONNEXA_BASE_URL` environment variable or the credentials profile.",
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"profile": {
+				Description: "The profile of the `~/.cloudconnexa/credentials` file to read `base_url`, `client_id` and `client_secret` from. " +
+					"The profile must set all three, which cannot then be set in the provider configuration. " +
+					"The value can be sourced from the `CLOUDCONNEXA_PROFILE` environment variable. Defaults to `default`, which is only used when none of the three are set otherwise.",
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"max_retries": {
+				Description:  "The maximum number of times a request is retried when the Cloud Connexa API responds with `429 Too Many Requests`, or with a `5xx` error to a request that does not create an object. Requests that create objects are not retried after a `5xx` error, as the object may have been created. Set to `0` to disable retries. Defaults to `3`.",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      3,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"retry_min_wait": {
+				Description:  "The minimum time in seconds to wait before retrying a request. The wait doubles with every attempt and is randomized to spread out retries. Defaults to `1`.",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      1,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"retry_max_wait": {
+				Description:  "The maximum time in seconds to wait before retrying a request, including waits requested by the API through the `Retry-After` header. Defaults to `30`.",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      30,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"requests_per_second": {
+				Description:  "The maximum number of requests per second sent to the Cloud Connexa API by this provider instance. The limit is shared by all resources and data sources, including retries. Requests waiting for their turn are only bounded by the timeouts of the resources. Set to `0` to disable client-side rate limiting. Defaults to `1`.",
+				Type:         schema.TypeFloat,
+				Optional:     true,
+				Default:      1.0,
+				ValidateFunc: validation.FloatAtLeast(0),
+			},
+			"burst": {
+				Description:  "The number of requests that may be sent at once before `requests_per_second` applies. Defaults to `5`.",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      5,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"proxy_url": {
+				Description:  "The URL of the HTTP(S) or SOCKS5 proxy to send requests through, for example `http://proxy.example.com:3128`. Defaults to the proxy set by the `HTTPS_PROXY` and `NO_PROXY` environment variables.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateProxyUrl,
+			},
+			"ca_cert_file": {
+				Description: "The path to a PEM file with additional CA certificates to trust, for example the CA of an intercepting proxy.",
 				Type:        schema.TypeString,
 				Optional:    true,
-				Sensitive:   true,
-				DefaultFunc: schema.EnvDefaultFunc(ClientSecretEnvVar, nil),
 			},
-			"base_url": {
-				Description: "The target Cloud Connexa Base API URL in the format `https://[companyName].api.openvpn.com`",
+			"ca_cert_pem": {
+				Description: "PEM encoded additional CA certificates to trust.",
 				Type:        schema.TypeString,
-				Required:    true,
+				Optional:    true,
+			},
+			"insecure_skip_verify": {
+				Description: "Disables the verification of TLS certificates. This makes the connection vulnerable to interception and should only be used for debugging. Defaults to `false`.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
+			"client_cert_file": {
+				Description:   "The path to a PEM encoded client certificate presented for mutual TLS. Requires `client_key_file`.",
+				Type:          schema.TypeString,
+				Optional:      true,
+				RequiredWith:  []string{"client_key_file"},
+				ConflictsWith: []string{"client_cert_pem"},
+			},
+			"client_key_file": {
+				Description:   "The path to the PEM encoded private key of `client_cert_file`.",
+				Type:          schema.TypeString,
+				Optional:      true,
+				RequiredWith:  []string{"client_cert_file"},
+				ConflictsWith: []string{"client_key_pem"},
+			},
+			"client_cert_pem": {
+				Description:   "A PEM encoded client certificate presented for mutual TLS. Requires `client_key_pem`.",
+				Type:          schema.TypeString,
+				Optional:      true,
+				RequiredWith:  []string{"client_key_pem"},
+				ConflictsWith: []string{"client_cert_file"},
+			},
+			"client_key_pem": {
+				Description:   "The PEM encoded private key of `client_cert_pem`.",
+				Type:          schema.TypeString,
+				Optional:      true,
+				Sensitive:     true,
+				RequiredWith:  []string{"client_cert_pem"},
+				ConflictsWith: []string{"client_key_file"},
+			},
+			"read_only": {
+				Description: "Makes every create, update and delete fail before it reaches the Cloud Connexa API, so that plans can run safely with production credentials. " +
+					"Reads and data sources keep working. The value can be sourced from the `CLOUDCONNEXA_READ_ONLY` environment variable. Defaults to `false`.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc(ReadOnlyEnvVar, false),
 			},
 		},
 		ResourcesMap: map[string]*schema.Resource{
-			"cloudconnexa_network":    resourceNetwork(),
-			"cloudconnexa_connector":  resourceConnector(),
-			"cloudconnexa_route":      resourceRoute(),
-			"cloudconnexa_dns_record": resourceDnsRecord(),
-			"cloudconnexa_user":       resourceUser(),
-			"cloudconnexa_host":       resourceHost(),
-			"cloudconnexa_user_group": resourceUserGroup(),
-			"cloudconnexa_ip_service": resourceIPService(),
+			"cloudconnexa_network":        resourceNetwork(),
+			"cloudconnexa_connector":      resourceConnector(),
+			"cloudconnexa_route":          resourceRoute(),
+			"cloudconnexa_dns_record":     resourceDnsRecord(),
+			"cloudconnexa_user":           resourceUser(),
+			"cloudconnexa_host":           resourceHost(),
+			"cloudconnexa_user_group":     resourceUserGroup(),
+			"cloudconnexa_ip_service":     resourceIPService(),
+			"cloudconnexa_network_routes": resourceNetworkRoutes(),
 		},
 
 		DataSourcesMap: map[string]*schema.Resource{
-			"cloudconnexa_network":        dataSourceNetwork(),
-			"cloudconnexa_connector":      dataSourceConnector(),
-			"cloudconnexa_user":           dataSourceUser(),
-			"cloudconnexa_user_group":     dataSourceUserGroup(),
-			"cloudconnexa_vpn_region":     dataSourceVpnRegion(),
-			"cloudconnexa_network_routes": dataSourceNetworkRoutes(),
-			"cloudconnexa_host":           dataSourceHost(),
-			"cloudconnexa_ip_service":     dataSourceIPService(),
+			"cloudconnexa_network":             dataSourceNetwork(),
+			"cloudconnexa_connector":           dataSourceConnector(),
+			"cloudconnexa_connector_bootstrap": dataSourceConnectorBootstrap(),
+			"cloudconnexa_connector_manifests": dataSourceConnectorManifests(),
+			"cloudconnexa_user":                dataSourceUser(),
+			"cloudconnexa_user_group":          dataSourceUserGroup(),
+			"cloudconnexa_vpn_region":          dataSourceVpnRegion(),
+			"cloudconnexa_network_routes":      dataSourceNetworkRoutes(),
+			"cloudconnexa_host":                dataSourceHost(),
+			"cloudconnexa_ip_service":          dataSourceIPService(),
 		},
 		ConfigureContextFunc: providerConfigure,
 	}
+	refuseMutationsWhenReadOnly(p.ResourcesMap)
+	protectFromDeletion(p.ResourcesMap)
+	logWithSubsystems(p.ResourcesMap, p.DataSourcesMap)
+	attachAttributePaths(p.ResourcesMap, p.DataSourcesMap)
+	return p
 }
 
 func providerConfigure(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
-	clientId := d.Get("client_id").(string)
-	clientSecret := d.Get("client_secret").(string)
-	baseUrl := d.Get("base_url").(string)
-	cloudConnexaClient, err := cloudconnexa.NewClient(baseUrl, clientId, clientSecret)
+	base, diags := newBaseTransport(d)
+	if diags.HasError() {
+		return nil, diags
+	}
+	client, configureDiags := configureProvider(ctx, d, base)
+	return client, append(diags, configureDiags...)
+}
+
+// configureProvider creates the API client of the provider. All requests are sent
+// through base, which the tests replace to record or replay the API traffic.
+func configureProvider(ctx context.Context, d *schema.ResourceData, base http.RoundTripper) (interface{}, diag.Diagnostics) {
 	var diags diag.Diagnostics
+	creds, err := resolveCredentials(ctx, d)
+	if err != nil {
+		return nil, append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to load credentials profile",
+			Detail:   fmt.Sprintf("Error: %v", err),
+		})
+	}
+	if creds.BaseURL == "" {
+		return nil, append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Missing base_url",
+			Detail:   fmt.Sprintf("The base URL must be set in the provider configuration, the %s environment variable or the credentials profile.", BaseURLEnvVar),
+		})
+	}
+	retryMinWait := time.Duration(d.Get("retry_min_wait").(int)) * time.Second
+	retryMaxWait := time.Duration(d.Get("retry_max_wait").(int)) * time.Second
+	if retryMinWait > retryMaxWait {
+		return nil, append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Invalid retry settings",
+			Detail:   fmt.Sprintf("retry_min_wait (%v) must not be greater than retry_max_wait (%v)", retryMinWait, retryMaxWait),
+		})
+	}
+	limiter := newRateLimiter(d.Get("requests_per_second").(float64), d.Get("burst").(int))
+	transport := &retryTransport{
+		next: &rateLimitTransport{
+			next:    &timeoutTransport{next: &loggingTransport{next: base}, timeout: requestTimeout},
+			limiter: limiter,
+		},
+		maxRetries: d.Get("max_retries").(int),
+		minWait:    retryMinWait,
+		maxWait:    retryMaxWait,
+	}
+	cloudConnexaClient, err := newClient(ctx, creds.BaseURL, creds.ClientID, creds.ClientSecret, transport)
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
@@ -82,5 +240,6 @@ func providerConfigure(ctx context.Context, d *schema.ResourceData) (interface{}
 		})
 		return nil, diags
 	}
+	cloudConnexaClient.readOnly = d.Get("read_only").(bool)
 	return cloudConnexaClient, nil
 }
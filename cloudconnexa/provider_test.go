@@ -2,10 +2,13 @@ package cloudconnexa
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"strings"
 	"testing"
 
+	"github.com/OpenVPN/terraform-provider-openvpn-cloud/internal/fakeapi"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/openvpn/cloudconnexa-go-client/v2/cloudconnexa"
 
@@ -17,11 +20,16 @@ import (
 const alphabet = "abcdefghigklmnopqrstuvwxyz"
 
 var testCloudID = os.Getenv("CLOUDCONNEXA_TEST_ORGANIZATION")
+var testAccBaseURL = "https://" + testCloudID + ".api.openvpn.com"
+var testAccFakeAPI *fakeapi.Server
 var testAccProvider *schema.Provider
 var testAccProviderFactories map[string]func() (*schema.Provider, error)
 
 func init() {
 	testAccProvider = Provider()
+	testAccProvider.ConfigureContextFunc = func(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
+		return configureProvider(ctx, d, testAccTransport)
+	}
 	testAccProviderFactories = map[string]func() (*schema.Provider, error){
 		"cloudconnexa": func() (*schema.Provider, error) {
 			return testAccProvider, nil
@@ -29,6 +37,86 @@ func init() {
 	}
 }
 
+// TestMain runs the acceptance tests against an in-process fake of the Cloud Connexa
+// API unless credentials for a real tenant are provided or the recorded cassettes are
+// replayed.
+func TestMain(m *testing.M) {
+	if testAccCassetteMode == cassetteModeReplay {
+		// Nothing is sent, but the provider still requires a URL and credentials.
+		testAccBaseURL = "https://replay.api.openvpn.com"
+		os.Setenv(ClientIDEnvVar, redacted)
+		os.Setenv(ClientSecretEnvVar, redacted)
+	} else if os.Getenv(ClientIDEnvVar) == "" && os.Getenv(ClientSecretEnvVar) == "" {
+		testAccFakeAPI = fakeapi.NewServer()
+		testAccBaseURL = testAccFakeAPI.URL
+		os.Setenv(ClientIDEnvVar, testAccFakeAPI.ClientID)
+		os.Setenv(ClientSecretEnvVar, testAccFakeAPI.ClientSecret)
+	}
+	code := m.Run()
+	if testAccFakeAPI != nil {
+		testAccFakeAPI.Close()
+	}
+	os.Exit(code)
+}
+
+// testAccProviderConfig returns the provider block used by the acceptance tests.
+func testAccProviderConfig() string {
+	if testAccFakeAPI != nil || testAccCassetteMode == cassetteModeReplay {
+		// Neither the fake API nor a cassette throttles, so neither does the provider.
+		return fmt.Sprintf(`
+provider "cloudconnexa" {
+  base_url            = "%s"
+  requests_per_second = 0
+}
+`, testAccBaseURL)
+	}
+	return fmt.Sprintf(`
+provider "cloudconnexa" {
+  base_url = "%s"
+}
+`, testAccBaseURL)
+}
+
+// newTestProvider returns a provider configured against the fake API.
+func newTestProvider(t *testing.T, server *fakeapi.Server) *schema.Provider {
+	t.Setenv("HOME", t.TempDir())
+	p := Provider()
+	diags := p.Configure(context.Background(), terraform.NewResourceConfigRaw(map[string]interface{}{
+		"base_url":            server.URL,
+		"client_id":           server.ClientID,
+		"client_secret":       server.ClientSecret,
+		"requests_per_second": 0,
+	}))
+	require.False(t, diags.HasError(), "%v", diags)
+	return p
+}
+
+// testImportStateVerify imports the object with the given import ID and checks that the
+// imported state matches expected except for the ignored attributes, the way
+// ImportStateVerify does in acceptance tests.
+func testImportStateVerify(t *testing.T, r *schema.Resource, importId string, expected *terraform.InstanceState, meta interface{}, ignore ...string) {
+	ctx := context.Background()
+	imported, err := r.Importer.StateContext(ctx, r.Data(&terraform.InstanceState{ID: importId}), meta)
+	require.NoError(t, err, importId)
+	require.Len(t, imported, 1, importId)
+	state, diags := r.RefreshWithoutUpgrade(ctx, imported[0].State(), meta)
+	require.False(t, diags.HasError(), "%s: %v", importId, diags)
+	require.NotNil(t, state, importId)
+	assert.Equal(t, expected.ID, state.ID, importId)
+	expectedAttributes, actualAttributes := map[string]string{}, map[string]string{}
+	for k, v := range expected.Attributes {
+		expectedAttributes[k] = v
+	}
+	for k, v := range state.Attributes {
+		actualAttributes[k] = v
+	}
+	for _, k := range ignore {
+		delete(expectedAttributes, k)
+		delete(actualAttributes, k)
+	}
+	assert.Equal(t, expectedAttributes, actualAttributes, importId)
+}
+
 func TestProvider(t *testing.T) {
 	err := Provider().InternalValidate()
 	require.NoError(t, err)
@@ -36,8 +124,10 @@ func TestProvider(t *testing.T) {
 	// must have the required error when the credentials are not set
 	t.Setenv(ClientIDEnvVar, "")
 	t.Setenv(ClientSecretEnvVar, "")
-	rc := terraform.ResourceConfig{}
-	diags := Provider().Configure(context.Background(), &rc)
+	t.Setenv(ProfileEnvVar, "")
+	t.Setenv("HOME", t.TempDir())
+	rc := terraform.NewResourceConfigRaw(map[string]interface{}{"base_url": "https://example.api.openvpn.com"})
+	diags := Provider().Configure(context.Background(), rc)
 	assert.True(t, diags.HasError())
 
 	for _, d := range diags {
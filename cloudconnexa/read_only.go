@@ -0,0 +1,45 @@
+package cloudconnexa
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+type resourceFunc = func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics
+
+// refuseMutationsWhenReadOnly wraps the Create, Update and Delete functions of every
+// resource so that they fail before calling the API when the provider is read-only.
+func refuseMutationsWhenReadOnly(resources map[string]*schema.Resource) {
+	for name, r := range resources {
+		if r.CreateContext != nil {
+			r.CreateContext = refuseWhenReadOnly(name, "created", r.CreateContext)
+		}
+		if r.UpdateContext != nil {
+			r.UpdateContext = refuseWhenReadOnly(name, "updated", r.UpdateContext)
+		}
+		if r.DeleteContext != nil {
+			r.DeleteContext = refuseWhenReadOnly(name, "deleted", r.DeleteContext)
+		}
+	}
+}
+
+func refuseWhenReadOnly(resourceType, operation string, f resourceFunc) resourceFunc {
+	return func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+		if c, ok := m.(*apiClient); ok && c.readOnly {
+			subject := resourceType
+			if d.Id() != "" {
+				subject = fmt.Sprintf("%s %s", resourceType, d.Id())
+			}
+			return diag.Diagnostics{{
+				Severity: diag.Error,
+				Summary:  "The provider is read-only",
+				Detail: fmt.Sprintf("%s cannot be %s because read_only is enabled in the provider configuration or by the %s environment variable. "+
+					"Disable it to apply changes.", subject, operation, ReadOnlyEnvVar),
+			}}
+		}
+		return f(ctx, d, m)
+	}
+}
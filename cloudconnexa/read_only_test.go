@@ -0,0 +1,65 @@
+package cloudconnexa
+
+import (
+	"context"
+	"testing"
+
+	"github.com/OpenVPN/terraform-provider-openvpn-cloud/internal/fakeapi"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadOnly_refusesMutations(t *testing.T) {
+	// The client is nil, so any function that gets past the guard panics.
+	meta := &apiClient{readOnly: true}
+	ctx := context.Background()
+
+	for name, r := range Provider().ResourcesMap {
+		d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{})
+		diags := r.CreateContext(ctx, d, meta)
+		require.True(t, diags.HasError(), name)
+		assert.Equal(t, "The provider is read-only", diags[0].Summary)
+		assert.Contains(t, diags[0].Detail, name+" cannot be created")
+
+		d.SetId("id")
+		if r.UpdateContext != nil {
+			diags = r.UpdateContext(ctx, d, meta)
+			require.True(t, diags.HasError(), name)
+			assert.Contains(t, diags[0].Detail, name+" id cannot be updated")
+		}
+		diags = r.DeleteContext(ctx, d, meta)
+		require.True(t, diags.HasError(), name)
+		assert.Contains(t, diags[0].Detail, name+" id cannot be deleted")
+	}
+}
+
+func TestReadOnly_keepsReadsWorking(t *testing.T) {
+	server := fakeapi.NewServer()
+	defer server.Close()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(ReadOnlyEnvVar, "true")
+
+	p := Provider()
+	diags := p.Configure(context.Background(), terraform.NewResourceConfigRaw(map[string]interface{}{
+		"base_url":      server.URL,
+		"client_id":     server.ClientID,
+		"client_secret": server.ClientSecret,
+	}))
+	require.False(t, diags.HasError(), "%v", diags)
+	meta := p.Meta().(*apiClient)
+	assert.True(t, meta.readOnly, "read_only must be sourced from the environment")
+
+	r := p.DataSourcesMap["cloudconnexa_vpn_region"]
+	d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{"region_id": "fi-hel"})
+	diags = r.ReadContext(context.Background(), d, meta)
+	require.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, "Finland", d.Get("country"))
+
+	network := p.ResourcesMap["cloudconnexa_network"]
+	d = schema.TestResourceDataRaw(t, network.Schema, map[string]interface{}{})
+	d.SetId("00000000-0000-4000-8000-000000000001")
+	diags = network.ReadContext(context.Background(), d, meta)
+	assert.False(t, diags.HasError(), "%v", diags)
+}
@@ -2,6 +2,8 @@ package cloudconnexa
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
 	"github.com/openvpn/cloudconnexa-go-client/v2/cloudconnexa"
 
@@ -15,35 +17,48 @@ func resourceConnector() *schema.Resource {
 		Description:   "Use `cloudconnexa_connector` to create an Cloud Connexa connector.\n\n~> NOTE: This only creates the Cloud Connexa connector object. Additional manual steps are required to associate a host in your infrastructure with the connector. Go to https://openvpn.net/cloud-docs/connector/ for more information.",
 		CreateContext: resourceConnectorCreate,
 		ReadContext:   resourceConnectorRead,
+		UpdateContext: resourceConnectorUpdate,
 		DeleteContext: resourceConnectorDelete,
+		CustomizeDiff: customizeDiffConnector,
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: resourceConnectorImport,
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(defaultTimeout),
+			Read:   schema.DefaultTimeout(defaultTimeout),
+			Update: schema.DefaultTimeout(defaultTimeout),
+			Delete: schema.DefaultTimeout(defaultTimeout),
 		},
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:        schema.TypeString,
 				Required:    true,
-				ForceNew:    true,
 				Description: "The connector display name.",
 			},
+			"description": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ValidateFunc: validation.StringLenBetween(1, 120),
+				Description:  "The display description for this resource. When it is not set, the description of the connector in Cloud Connexa is kept.",
+			},
 			"vpn_region_id": {
 				Type:        schema.TypeString,
 				Required:    true,
-				ForceNew:    true,
-				Description: "The id of the region where the connector will be deployed.",
+				Description: "The id of the region where the connector will be deployed. Changing it moves the connector and regenerates its profile, which must then be deployed again.",
 			},
 			"network_item_type": {
 				Type:         schema.TypeString,
 				Required:     true,
 				ForceNew:     true,
 				ValidateFunc: validation.StringInSlice([]string{"HOST", "NETWORK"}, false),
-				Description:  "The type of network item of the connector. Supported values are `HOST` and `NETWORK`.",
+				Description:  "The type of network item of the connector. Supported values are `HOST` and `NETWORK`. Changing it replaces the connector.",
 			},
 			"network_item_id": {
 				Type:        schema.TypeString,
 				Required:    true,
 				ForceNew:    true,
-				Description: "The id of the network with which this connector is associated.",
+				Description: "The id of the network with which this connector is associated. Changing it replaces the connector.",
 			},
 			"ip_v4_address": {
 				Type:        schema.TypeString,
@@ -60,33 +75,39 @@ func resourceConnector() *schema.Resource {
 				Computed:    true,
 				Description: "OpenVPN profile of the connector.",
 			},
+			"connection_status": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Whether the connector is connected to Cloud Connexa, such as `online` or `offline`.",
+			},
+			"wait_for_online":     waitForOnlineSchema(),
+			"deletion_protection": deletionProtectionSchema(),
 		},
 	}
 }
 
 func resourceConnectorCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*cloudconnexa.Client)
+	c := m.(*apiClient).withContext(ctx)
 	var diags diag.Diagnostics
-	name := d.Get("name").(string)
 	networkItemId := d.Get("network_item_id").(string)
 	networkItemType := d.Get("network_item_type").(string)
-	vpnRegionId := d.Get("vpn_region_id").(string)
-	connector := cloudconnexa.Connector{
-		Name:            name,
-		NetworkItemId:   networkItemId,
-		NetworkItemType: networkItemType,
-		VpnRegionId:     vpnRegionId,
-	}
-	conn, err := c.Connectors.Create(connector, networkItemId)
+	conn, err := createConnector(c, networkItemType, networkItemId, connectorRequestOf(d))
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromErr(ctx, err)
 	}
 	d.SetId(conn.Id)
 	profile, err := c.Connectors.GetProfile(conn.Id)
 	if err != nil {
-		return append(diags, diag.FromErr(err)...)
+		return append(diags, diagFromErr(ctx, err)...)
 	}
 	d.Set("profile", profile)
+	d.Set("connection_status", string(conn.ConnectionStatus))
+	if d.Get("wait_for_online").(bool) {
+		if err := waitForConnectorsOnline(ctx, c, []string{conn.Id}); err != nil {
+			return append(diags, diagFromErr(ctx, err)...)
+		}
+		return append(diags, resourceConnectorRead(ctx, d, m)...)
+	}
 	return append(diags, diag.Diagnostic{
 		Severity: diag.Warning,
 		Summary:  "Connector needs to be set up manually",
@@ -95,46 +116,119 @@ func resourceConnectorCreate(ctx context.Context, d *schema.ResourceData, m inte
 }
 
 func resourceConnectorRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*cloudconnexa.Client)
+	c := m.(*apiClient).withContext(ctx)
 	var diags diag.Diagnostics
-	connector, err := c.Connectors.GetByID(d.Id())
+	connector, err := getConnector(c, d.Id())
 	if err != nil {
-		return append(diags, diag.FromErr(err)...)
+		return append(diags, diagFromErr(ctx, err)...)
 	}
 	if connector == nil {
-		d.SetId("")
-	} else {
-		d.SetId(connector.Id)
-		d.Set("name", connector.Name)
-		d.Set("vpn_region_id", connector.VpnRegionId)
-		d.Set("network_item_type", connector.NetworkItemType)
-		d.Set("network_item_id", connector.NetworkItemId)
-		d.Set("ip_v4_address", connector.IPv4Address)
-		d.Set("ip_v6_address", connector.IPv6Address)
-		profile, err := c.Connectors.GetProfile(connector.Id)
+		return append(diags, removeFromState(d, "connector")...)
+	}
+	d.Set("name", connector.Name)
+	d.Set("description", connector.Description)
+	d.Set("vpn_region_id", connector.VpnRegionId)
+	d.Set("network_item_type", connector.NetworkItemType)
+	d.Set("network_item_id", connector.NetworkItemId)
+	d.Set("ip_v4_address", connector.IPv4Address)
+	d.Set("ip_v6_address", connector.IPv6Address)
+	d.Set("connection_status", string(connector.ConnectionStatus))
+	profile, err := c.Connectors.GetProfile(connector.Id)
+	if isNotFound(err) {
+		return append(diags, removeFromState(d, "connector")...)
+	}
+	if err != nil {
+		return append(diags, diagFromErr(ctx, err)...)
+	}
+	d.Set("profile", profile)
+	return diags
+}
+
+// resourceConnectorUpdate renames, describes and moves the connector in place. Only
+// moving it to another network item replaces it.
+func resourceConnectorUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*apiClient).withContext(ctx)
+	var diags diag.Diagnostics
+	// Keep the prior state if a request fails, so that the next apply retries the update.
+	d.Partial(true)
+	if d.HasChanges("name", "description", "vpn_region_id") {
+		request := connectorRequestOf(d)
+		err := updateConnector(c, d.Id(), d.Get("network_item_type").(string), d.Get("network_item_id").(string), request)
 		if err != nil {
-			return append(diags, diag.FromErr(err)...)
+			return append(diags, diagFromErr(ctx, err)...)
+		}
+		// Check that the API applied the update, rather than leave Terraform to report
+		// a result inconsistent with the plan without a way out.
+		connector, err := getConnector(c, d.Id())
+		if err != nil {
+			return append(diags, diagFromErr(ctx, err)...)
+		}
+		if unapplied := unappliedConnectorChanges(request, connector); len(unapplied) > 0 {
+			return append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Connector was not updated in place",
+				Detail:   fmt.Sprintf("Cloud Connexa accepted the update of connector %s but did not change its %s. Replace the connector instead, with `terraform apply -replace` and the address of this resource.", d.Id(), strings.Join(unapplied, ", ")),
+			})
 		}
-		d.Set("profile", profile)
 	}
-	return diags
+	d.Partial(false)
+	return append(diags, resourceConnectorRead(ctx, d, m)...)
+}
+
+// resourceConnectorImport records the default of `wait_for_online`, which only exists
+// in Terraform.
+func resourceConnectorImport(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	d.Set("wait_for_online", false)
+	return []*schema.ResourceData{d}, nil
+}
+
+func connectorRequestOf(d *schema.ResourceData) connectorRequest {
+	return connectorRequest{
+		Name:        d.Get("name").(string),
+		Description: d.Get("description").(string),
+		VpnRegionId: d.Get("vpn_region_id").(string),
+	}
+}
+
+// unappliedConnectorChanges returns the attributes of request that connector does not
+// have. A connector that no longer exists is left for Read to remove from the state.
+func unappliedConnectorChanges(request connectorRequest, connector *describedConnector) []string {
+	if connector == nil {
+		return nil
+	}
+	var unapplied []string
+	if connector.Name != request.Name {
+		unapplied = append(unapplied, "name")
+	}
+	if connector.Description != request.Description {
+		unapplied = append(unapplied, "description")
+	}
+	if connector.VpnRegionId != request.VpnRegionId {
+		unapplied = append(unapplied, "vpn_region_id")
+	}
+	return unapplied
+}
+
+// customizeDiffConnector plans a new profile when the connector moves to another
+// region, as the profile points at the servers of the region.
+func customizeDiffConnector(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if d.Id() != "" && d.HasChange("vpn_region_id") {
+		return d.SetNewComputed("profile")
+	}
+	return nil
 }
 
 func resourceConnectorDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*cloudconnexa.Client)
+	c := m.(*apiClient).withContext(ctx)
 	var diags diag.Diagnostics
 	err := c.Connectors.Delete(d.Id(), d.Get("network_item_id").(string), d.Get("network_item_type").(string))
 	if err != nil {
-		return append(diags, diag.FromErr(err)...)
+		return append(diags, diagFromErr(ctx, err)...)
 	}
 	return diags
 }
 
-func getConnectorSlice(connectors []cloudconnexa.Connector, networkItemId string, connectorName string, m interface{}) ([]interface{}, error) {
-	if len(connectors) == 0 {
-		return nil, nil
-	}
-	connectorsList := make([]interface{}, 1)
+func getConnectorSlice(client *cloudconnexa.Client, connectors []cloudconnexa.Connector, networkItemId string, connectorName string) ([]interface{}, error) {
 	for _, c := range connectors {
 		if c.NetworkItemId == networkItemId && c.Name == connectorName {
 			connector := make(map[string]interface{})
@@ -145,15 +239,14 @@ func getConnectorSlice(connectors []cloudconnexa.Connector, networkItemId string
 			connector["vpn_region_id"] = c.VpnRegionId
 			connector["ip_v4_address"] = c.IPv4Address
 			connector["ip_v6_address"] = c.IPv6Address
-			client := m.(*cloudconnexa.Client)
+			connector["connection_status"] = string(c.ConnectionStatus)
 			profile, err := client.Connectors.GetProfile(c.Id)
 			if err != nil {
 				return nil, err
 			}
 			connector["profile"] = profile
-			connectorsList[0] = connector
-			break
+			return []interface{}{connector}, nil
 		}
 	}
-	return connectorsList, nil
+	return nil, nil
 }
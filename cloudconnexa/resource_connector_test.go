@@ -1,17 +1,23 @@
 package cloudconnexa
 
 import (
+	"context"
 	"fmt"
-	"github.com/openvpn/cloudconnexa-go-client/v2/cloudconnexa"
+	"net/http"
+	"regexp"
 	"testing"
 
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/OpenVPN/terraform-provider-openvpn-cloud/internal/fakeapi"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/openvpn/cloudconnexa-go-client/v2/cloudconnexa"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestAccCloudConnexaConnector_basic(t *testing.T) {
-	rName := acctest.RandomWithPrefix("test-connector")
+	random := testAccUseCassette(t)
+	rName := random.RandomWithPrefix("test-connector")
 	resourceName := "cloudconnexa_connector.test"
 
 	resource.Test(t, resource.TestCase{
@@ -20,10 +26,11 @@ func TestAccCloudConnexaConnector_basic(t *testing.T) {
 		CheckDestroy:      testAccCheckCloudConnexaConnectorDestroy,
 		Steps: []resource.TestStep{
 			{
-				Config: testAccCloudConnexaConnectorConfigBasic(rName),
+				Config: testAccCloudConnexaConnectorConfigBasic(rName, rName, "Managed by Terraform"),
 				Check: resource.ComposeTestCheckFunc(
 					testAccCheckCloudConnexaConnectorExists(resourceName),
 					resource.TestCheckResourceAttr(resourceName, "name", rName),
+					resource.TestCheckResourceAttr(resourceName, "description", "Managed by Terraform"),
 					resource.TestCheckResourceAttrSet(resourceName, "vpn_region_id"),
 					resource.TestCheckResourceAttrSet(resourceName, "network_item_type"),
 					resource.TestCheckResourceAttrSet(resourceName, "network_item_id"),
@@ -31,6 +38,20 @@ func TestAccCloudConnexaConnector_basic(t *testing.T) {
 					resource.TestCheckResourceAttrSet(resourceName, "ip_v6_address"),
 				),
 			},
+			{
+				Config: testAccCloudConnexaConnectorConfigBasic(rName, rName+"-renamed", "Renamed"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckCloudConnexaConnectorExists(resourceName),
+					resource.TestCheckResourceAttr(resourceName, "name", rName+"-renamed"),
+					resource.TestCheckResourceAttr(resourceName, "description", "Renamed"),
+				),
+			},
+			{
+				ResourceName:            resourceName,
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"deletion_protection"},
+			},
 		},
 	})
 }
@@ -49,7 +70,7 @@ func testAccCheckCloudConnexaConnectorExists(n string) resource.TestCheckFunc {
 }
 
 func testAccCheckCloudConnexaConnectorDestroy(s *terraform.State) error {
-	client := testAccProvider.Meta().(*cloudconnexa.Client)
+	client := testAccProvider.Meta().(*apiClient).client
 
 	for _, rs := range s.RootModule().Resources {
 		if rs.Type != "cloudconnexa_connector" {
@@ -71,17 +92,157 @@ func testAccCheckCloudConnexaConnectorDestroy(s *terraform.State) error {
 	return nil
 }
 
-func testAccCloudConnexaConnectorConfigBasic(rName string) string {
-	return fmt.Sprintf(`
-provider "cloudconnexa" {
-  base_url = "https://%[1]s.api.openvpn.com"
+func testAccCloudConnexaConnectorConfigBasic(rName, connectorName, description string) string {
+	return fmt.Sprintf(`%[1]s
+resource "cloudconnexa_host" "test" {
+  name = "%[2]s"
+  connector {
+    name          = "%[2]s-host"
+    vpn_region_id = "us-west-1"
+  }
 }
 
 resource "cloudconnexa_connector" "test" {
-  name              = "%s"
+  name              = "%[3]s"
+  description       = "%[4]s"
   vpn_region_id     = "us-west-1"
   network_item_type = "HOST"
-  network_item_id   = "example_network_item_id"
+  network_item_id   = cloudconnexa_host.test.id
+}
+`, testAccProviderConfig(), rName, connectorName, description)
+}
+
+func TestResourceConnector_updatesInPlace(t *testing.T) {
+	server := fakeapi.NewServer()
+	defer server.Close()
+	p := newTestProvider(t, server)
+	meta := p.Meta().(*apiClient)
+	network := testApply(t, p.ResourcesMap["cloudconnexa_network"], nil, map[string]interface{}{
+		"name":          "network",
+		"connector":     []interface{}{map[string]interface{}{"name": "west", "vpn_region_id": "us-west-1"}},
+		"default_route": []interface{}{map[string]interface{}{"subnet": "10.1.0.0/16"}},
+	}, meta)
+
+	r := p.ResourcesMap["cloudconnexa_connector"]
+	config := func(name, description, region string) map[string]interface{} {
+		return map[string]interface{}{
+			"name":              name,
+			"description":       description,
+			"vpn_region_id":     region,
+			"network_item_type": "NETWORK",
+			"network_item_id":   network.ID,
+		}
+	}
+	state := testApply(t, r, nil, config("connector", "first", "us-west-1"), meta)
+	id, profile := state.ID, state.Attributes["profile"]
+	assert.Equal(t, "first", state.Attributes["description"])
+
+	for _, raw := range []map[string]interface{}{
+		config("renamed", "first", "us-west-1"),
+		config("renamed", "second", "us-west-1"),
+		config("renamed", "second", "fi-hel"),
+	} {
+		diff, err := r.Diff(context.Background(), state, terraform.NewResourceConfigRaw(raw), meta)
+		require.NoError(t, err)
+		require.False(t, diff.RequiresNew(), "%v", raw)
+		state = testApply(t, r, state, raw, meta)
+		assert.Equal(t, id, state.ID)
+	}
+	withoutDescription := config("renamed", "", "fi-hel")
+	delete(withoutDescription, "description")
+	diff, err := r.Diff(context.Background(), state, terraform.NewResourceConfigRaw(withoutDescription), meta)
+	require.NoError(t, err)
+	assert.True(t, diff == nil || diff.Empty(), "a connector without a configured description keeps its own: %v", diff)
+
+	connector, err := getConnector(meta.client, id)
+	require.NoError(t, err)
+	assert.Equal(t, "renamed", connector.Name)
+	assert.Equal(t, "second", connector.Description)
+	assert.Equal(t, "fi-hel", connector.VpnRegionId)
+	assert.NotEqual(t, profile, state.Attributes["profile"], "moving the connector regenerates its profile")
+
+	testImportStateVerify(t, r, id, state, meta)
+}
+
+func TestResourceConnector_reportsUnappliedUpdates(t *testing.T) {
+	server := fakeapi.NewUnstartedServer()
+	// As if the API accepted renames but ignored changes of region.
+	api := server.Config.Handler
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut && regexp.MustCompile(`^/api/beta/connectors/`).MatchString(r.URL.Path) {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		api.ServeHTTP(w, r)
+	})
+	server.Start()
+	defer server.Close()
+	p := newTestProvider(t, server)
+	meta := p.Meta().(*apiClient)
+	network, err := meta.client.Networks.Create(cloudconnexa.Network{
+		Name:       "network",
+		Connectors: []cloudconnexa.NetworkConnector{{Name: "west", VpnRegionId: "us-west-1"}},
+	})
+	require.NoError(t, err)
+
+	r := p.ResourcesMap["cloudconnexa_connector"]
+	config := func(region string) map[string]interface{} {
+		return map[string]interface{}{
+			"name":              "connector",
+			"vpn_region_id":     region,
+			"network_item_type": "NETWORK",
+			"network_item_id":   network.Id,
+		}
+	}
+	state := testApply(t, r, nil, config("us-west-1"), meta)
+	diff, err := r.Diff(context.Background(), state, terraform.NewResourceConfigRaw(config("fi-hel")), meta)
+	require.NoError(t, err)
+	_, diags := r.Apply(context.Background(), state, diff, meta)
+	require.True(t, diags.HasError())
+	assert.Equal(t, "Connector was not updated in place", diags[0].Summary)
+	assert.Contains(t, diags[0].Detail, "did not change its vpn_region_id")
 }
-`, testCloudID, rName)
+
+func TestResourceConnector_keepsThePriorStateOfFailedUpdates(t *testing.T) {
+	server := fakeapi.NewUnstartedServer()
+	failUpdates := false
+	api := server.Config.Handler
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failUpdates && r.Method == http.MethodPut && regexp.MustCompile(`^/api/beta/connectors/`).MatchString(r.URL.Path) {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		api.ServeHTTP(w, r)
+	})
+	server.Start()
+	defer server.Close()
+	p := newTestProvider(t, server)
+	meta := p.Meta().(*apiClient)
+	network, err := meta.client.Networks.Create(cloudconnexa.Network{
+		Name:       "network",
+		Connectors: []cloudconnexa.NetworkConnector{{Name: "west", VpnRegionId: "us-west-1"}},
+	})
+	require.NoError(t, err)
+
+	r := p.ResourcesMap["cloudconnexa_connector"]
+	config := func(name string) map[string]interface{} {
+		return map[string]interface{}{
+			"name":              name,
+			"vpn_region_id":     "us-west-1",
+			"network_item_type": "NETWORK",
+			"network_item_id":   network.Id,
+		}
+	}
+	state := testApply(t, r, nil, config("connector"), meta)
+
+	failUpdates = true
+	diff, err := r.Diff(context.Background(), state, terraform.NewResourceConfigRaw(config("renamed")), meta)
+	require.NoError(t, err)
+	failed, diags := r.Apply(context.Background(), state, diff, meta)
+	require.True(t, diags.HasError())
+	assert.Equal(t, "connector", failed.Attributes["name"], "a failed update must not be recorded")
+
+	failUpdates = false
+	state = testApply(t, r, failed, config("renamed"), meta)
+	assert.Equal(t, "renamed", state.Attributes["name"])
 }
@@ -19,6 +19,12 @@ func resourceDnsRecord() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(defaultTimeout),
+			Read:   schema.DefaultTimeout(defaultTimeout),
+			Update: schema.DefaultTimeout(defaultTimeout),
+			Delete: schema.DefaultTimeout(defaultTimeout),
+		},
 		Schema: map[string]*schema.Schema{
 			"domain": {
 				Type:        schema.TypeString,
@@ -56,7 +62,7 @@ func resourceDnsRecord() *schema.Resource {
 }
 
 func resourceDnsRecordCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*cloudconnexa.Client)
+	c := m.(*apiClient).withContext(ctx)
 	var diags diag.Diagnostics
 	domain := d.Get("domain").(string)
 	description := d.Get("description").(string)
@@ -78,33 +84,32 @@ func resourceDnsRecordCreate(ctx context.Context, d *schema.ResourceData, m inte
 	}
 	dnsRecord, err := c.DnsRecords.Create(dr)
 	if err != nil {
-		return append(diags, diag.FromErr(err)...)
+		return append(diags, diagFromErr(ctx, err)...)
 	}
 	d.SetId(dnsRecord.Id)
 	return diags
 }
 
 func resourceDnsRecordRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*cloudconnexa.Client)
+	c := m.(*apiClient).withContext(ctx)
 	var diags diag.Diagnostics
 	recordId := d.Id()
 	r, err := c.DnsRecords.GetDnsRecord(recordId)
-	if err != nil {
-		return append(diags, diag.FromErr(err)...)
+	if isNotFound(err) || (err == nil && r == nil) {
+		return append(diags, removeFromState(d, "DNS record")...)
 	}
-	if r == nil {
-		d.SetId("")
-	} else {
-		d.Set("domain", r.Domain)
-		d.Set("description", r.Description)
-		d.Set("ip_v4_addresses", r.IPV4Addresses)
-		d.Set("ip_v6_addresses", r.IPV6Addresses)
+	if err != nil {
+		return append(diags, diagFromErr(ctx, err)...)
 	}
+	d.Set("domain", r.Domain)
+	d.Set("description", r.Description)
+	d.Set("ip_v4_addresses", r.IPV4Addresses)
+	d.Set("ip_v6_addresses", r.IPV6Addresses)
 	return diags
 }
 
 func resourceDnsRecordUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*cloudconnexa.Client)
+	c := m.(*apiClient).withContext(ctx)
 	var diags diag.Diagnostics
 	_, domain := d.GetChange("domain")
 	_, description := d.GetChange("description")
@@ -121,18 +126,18 @@ func resourceDnsRecordUpdate(ctx context.Context, d *schema.ResourceData, m inte
 	}
 	err := c.DnsRecords.Update(dr)
 	if err != nil {
-		return append(diags, diag.FromErr(err)...)
+		return append(diags, diagFromErr(ctx, err)...)
 	}
 	return diags
 }
 
 func resourceDnsRecordDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*cloudconnexa.Client)
+	c := m.(*apiClient).withContext(ctx)
 	var diags diag.Diagnostics
 	routeId := d.Id()
 	err := c.DnsRecords.Delete(routeId)
 	if err != nil {
-		return append(diags, diag.FromErr(err)...)
+		return append(diags, diagFromErr(ctx, err)...)
 	}
 	return diags
 }
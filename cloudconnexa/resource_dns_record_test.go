@@ -2,7 +2,6 @@ package cloudconnexa
 
 import (
 	"fmt"
-	"github.com/openvpn/cloudconnexa-go-client/v2/cloudconnexa"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
@@ -10,6 +9,7 @@ import (
 )
 
 func TestAccCloudConnexaDnsRecord_basic(t *testing.T) {
+	testAccUseCassette(t)
 	resourceName := "cloudconnexa_dns_record.test"
 	domainName := "test.cloudconnexa.com"
 	resource.Test(t, resource.TestCase{
@@ -33,7 +33,7 @@ func TestAccCloudConnexaDnsRecord_basic(t *testing.T) {
 }
 
 func testAccCheckCloudConnexaDnsRecordDestroy(s *terraform.State) error {
-	client := testAccProvider.Meta().(*cloudconnexa.Client)
+	client := testAccProvider.Meta().(*apiClient).client
 
 	for _, rs := range s.RootModule().Resources {
 		if rs.Type != "cloudconnexa_dns_record" {
@@ -42,7 +42,9 @@ func testAccCheckCloudConnexaDnsRecordDestroy(s *terraform.State) error {
 
 		recordId := rs.Primary.ID
 		r, err := client.DnsRecords.GetDnsRecord(recordId)
-
+		if isNotFound(err) {
+			continue
+		}
 		if err != nil {
 			return err
 		}
@@ -56,16 +58,12 @@ func testAccCheckCloudConnexaDnsRecordDestroy(s *terraform.State) error {
 }
 
 func testAccCloudConnexaDnsRecordConfig(domainName string) string {
-	return fmt.Sprintf(`
-provider "cloudconnexa" {
-  base_url = "https://%[1]s.api.openvpn.com"
-}
-
+	return fmt.Sprintf(`%[1]s
 resource "cloudconnexa_dns_record" "test" {
   domain          = "%[2]s"
   description     = "test description"
   ip_v4_addresses = ["192.168.1.1", "192.168.1.2"]
   ip_v6_addresses = ["2001:db8:85a3:0:0:8a2e:370:7334", "2001:db8:85a3:0:0:8a2e:370:7335"]
 }
-`, testCloudID, domainName)
+`, testAccProviderConfig(), domainName)
 }
@@ -20,6 +20,12 @@ func resourceHost() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(defaultTimeout),
+			Read:   schema.DefaultTimeout(defaultTimeout),
+			Update: schema.DefaultTimeout(defaultTimeout),
+			Delete: schema.DefaultTimeout(defaultTimeout),
+		},
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:        schema.TypeString,
@@ -98,15 +104,22 @@ func resourceHost() *schema.Resource {
 							Type:     schema.TypeString,
 							Computed: true,
 						},
+						"connection_status": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Whether the connector is connected to Cloud Connexa, such as `online` or `offline`.",
+						},
+						"wait_for_online": waitForOnlineSchema(),
 					},
 				},
 			},
+			"deletion_protection": deletionProtectionSchema(),
 		},
 	}
 }
 
 func resourceHostCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*cloudconnexa.Client)
+	c := m.(*apiClient).withContext(ctx)
 	var diags diag.Diagnostics
 	var connectors []cloudconnexa.Connector
 	configConnectors := d.Get("connector").(*schema.Set)
@@ -124,13 +137,19 @@ func resourceHostCreate(ctx context.Context, d *schema.ResourceData, m interface
 	}
 	host, err := c.Hosts.Create(h)
 	if err != nil {
-		return append(diags, diag.FromErr(err)...)
+		return append(diags, diagFromErr(ctx, err)...)
 	}
 	d.SetId(host.Id)
-	diagnostics := setConnectorsList(d, c, host.Connectors)
+	diagnostics := setConnectorsList(ctx, d, c, host.Connectors)
 	if diagnostics != nil {
 		return diagnostics
 	}
+	if waitFor := connectorsToWaitFor(configConnectors, host.Connectors); len(waitFor) > 0 {
+		if err := waitForConnectorsOnline(ctx, c, waitFor); err != nil {
+			return append(diags, diagFromErr(ctx, err)...)
+		}
+		return append(diags, resourceHostRead(ctx, d, m)...)
+	}
 
 	return append(diags, diag.Diagnostic{
 		Severity: diag.Warning,
@@ -140,22 +159,21 @@ func resourceHostCreate(ctx context.Context, d *schema.ResourceData, m interface
 }
 
 func resourceHostRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*cloudconnexa.Client)
+	c := m.(*apiClient).withContext(ctx)
 	var diags diag.Diagnostics
 	host, err := c.Hosts.Get(d.Id())
 	if err != nil {
-		return append(diags, diag.FromErr(err)...)
+		return append(diags, diagFromErr(ctx, err)...)
 	}
 	if host == nil {
-		d.SetId("")
-		return diags
+		return append(diags, removeFromState(d, "host")...)
 	}
 	d.Set("name", host.Name)
 	d.Set("description", host.Description)
 	d.Set("internet_access", host.InternetAccess)
 	d.Set("system_subnets", host.SystemSubnets)
 
-	diagnostics := setConnectorsList(d, c, host.Connectors)
+	diagnostics := setConnectorsList(ctx, d, c, host.Connectors)
 	if diagnostics != nil {
 		return diagnostics
 	}
@@ -163,8 +181,9 @@ func resourceHostRead(ctx context.Context, d *schema.ResourceData, m interface{}
 }
 
 func resourceHostUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*cloudconnexa.Client)
+	c := m.(*apiClient).withContext(ctx)
 	var diags diag.Diagnostics
+	var created []cloudconnexa.Connector
 	if d.HasChange("connector") {
 		old, new := d.GetChange("connector")
 		oldSet := old.(*schema.Set)
@@ -176,16 +195,17 @@ func resourceHostUpdate(ctx context.Context, d *schema.ResourceData, m interface
 				VpnRegionId:     newSet.List()[0].(map[string]interface{})["vpn_region_id"].(string),
 				NetworkItemType: "HOST",
 			}
-			_, err := c.Connectors.Create(newConnector, d.Id())
+			connector, err := c.Connectors.Create(newConnector, d.Id())
 			if err != nil {
-				return append(diags, diag.FromErr(err)...)
+				return append(diags, diagFromErr(ctx, err)...)
 			}
+			created = append(created, *connector)
 		} else {
 			for _, o := range oldSet.List() {
 				if !newSet.Contains(o) {
 					err := c.Connectors.Delete(o.(map[string]interface{})["id"].(string), d.Id(), "HOST")
 					if err != nil {
-						diags = append(diags, diag.FromErr(err)...)
+						diags = append(diags, diagFromErr(ctx, err)...)
 					}
 				}
 			}
@@ -196,9 +216,11 @@ func resourceHostUpdate(ctx context.Context, d *schema.ResourceData, m interface
 						VpnRegionId:     n.(map[string]interface{})["vpn_region_id"].(string),
 						NetworkItemType: "HOST",
 					}
-					_, err := c.Connectors.Create(newConnector, d.Id())
+					connector, err := c.Connectors.Create(newConnector, d.Id())
 					if err != nil {
-						diags = append(diags, diag.FromErr(err)...)
+						diags = append(diags, diagFromErr(ctx, err)...)
+					} else {
+						created = append(created, *connector)
 					}
 				}
 			}
@@ -215,35 +237,49 @@ func resourceHostUpdate(ctx context.Context, d *schema.ResourceData, m interface
 			InternetAccess: newAccess.(string),
 		})
 		if err != nil {
-			return append(diags, diag.FromErr(err)...)
+			return append(diags, diagFromErr(ctx, err)...)
 		}
 	}
+	if err := waitForConnectorsOnline(ctx, c, connectorsToWaitFor(d.Get("connector").(*schema.Set), created)); err != nil {
+		return append(diags, diagFromErr(ctx, err)...)
+	}
 	return append(diags, resourceHostRead(ctx, d, m)...)
 }
 
 func resourceHostDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*cloudconnexa.Client)
+	c := m.(*apiClient).withContext(ctx)
 	var diags diag.Diagnostics
 	hostId := d.Id()
 	err := c.Hosts.Delete(hostId)
 	if err != nil {
-		return append(diags, diag.FromErr(err)...)
+		return append(diags, diagFromErr(ctx, err)...)
 	}
 	return diags
 }
 
-func setConnectorsList(data *schema.ResourceData, c *cloudconnexa.Client, connectors []cloudconnexa.Connector) diag.Diagnostics {
+func setConnectorsList(ctx context.Context, data *schema.ResourceData, c *cloudconnexa.Client, connectors []cloudconnexa.Connector) diag.Diagnostics {
+	// wait_for_online only exists in Terraform, so it is carried over from the
+	// configured connector of the same name and region.
+	waitForOnline := map[string]interface{}{}
+	for _, v := range data.Get("connector").(*schema.Set).List() {
+		configConnector := v.(map[string]interface{})
+		waitForOnline[connectorKey(configConnector["name"].(string), configConnector["vpn_region_id"].(string))] = configConnector["wait_for_online"]
+	}
 	connectorsList := make([]interface{}, len(connectors))
 	for i, connector := range connectors {
 		connectorsData, err := getConnectorsListItem(c, connector)
 		if err != nil {
-			return diag.FromErr(err)
+			return diagFromErr(ctx, err)
+		}
+		connectorsData["wait_for_online"] = false
+		if wait, ok := waitForOnline[connectorKey(connector.Name, connector.VpnRegionId)]; ok && wait != nil {
+			connectorsData["wait_for_online"] = wait
 		}
 		connectorsList[i] = connectorsData
 	}
 	err := data.Set("connector", connectorsList)
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromErr(ctx, err)
 	}
 	return nil
 }
@@ -257,6 +293,7 @@ func getConnectorsListItem(c *cloudconnexa.Client, connector cloudconnexa.Connec
 		"ip_v6_address":     connector.IPv6Address,
 		"network_item_id":   connector.NetworkItemId,
 		"network_item_type": connector.NetworkItemType,
+		"connection_status": string(connector.ConnectionStatus),
 	}
 
 	connectorProfile, err := c.Connectors.GetProfile(connector.Id)
@@ -266,3 +303,26 @@ func getConnectorsListItem(c *cloudconnexa.Client, connector cloudconnexa.Connec
 	connectorsData["profile"] = connectorProfile
 	return connectorsData, nil
 }
+
+func connectorKey(name, vpnRegionId string) string {
+	return name + "\x00" + vpnRegionId
+}
+
+// connectorsToWaitFor returns the IDs of the connectors whose configured connector
+// block has `wait_for_online` set.
+func connectorsToWaitFor(configConnectors *schema.Set, connectors []cloudconnexa.Connector) []string {
+	wait := map[string]bool{}
+	for _, v := range configConnectors.List() {
+		configConnector := v.(map[string]interface{})
+		if configConnector["wait_for_online"].(bool) {
+			wait[connectorKey(configConnector["name"].(string), configConnector["vpn_region_id"].(string))] = true
+		}
+	}
+	var ids []string
+	for _, connector := range connectors {
+		if wait[connectorKey(connector.Name, connector.VpnRegionId)] {
+			ids = append(ids, connector.Id)
+		}
+	}
+	return ids
+}
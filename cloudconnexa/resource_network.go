@@ -2,10 +2,13 @@ package cloudconnexa
 
 import (
 	"context"
+	"hash/fnv"
+	"sort"
 
 	"github.com/openvpn/cloudconnexa-go-client/v2/cloudconnexa"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
@@ -17,8 +20,18 @@ func resourceNetwork() *schema.Resource {
 		ReadContext:   resourceNetworkRead,
 		UpdateContext: resourceNetworkUpdate,
 		DeleteContext: resourceNetworkDelete,
+		CustomizeDiff: customdiff.Sequence(
+			customizeDiffRouteValue("default_route.0.type", "default_route.0.subnet"),
+			customizeDiffNetwork,
+		),
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: resourceNetworkImport,
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(defaultTimeout),
+			Read:   schema.DefaultTimeout(defaultTimeout),
+			Update: schema.DefaultTimeout(defaultTimeout),
+			Delete: schema.DefaultTimeout(defaultTimeout),
 		},
 		Schema: map[string]*schema.Schema{
 			"name": {
@@ -65,7 +78,7 @@ func resourceNetwork() *schema.Resource {
 							Type:         schema.TypeString,
 							Optional:     true,
 							Default:      "IP_V4",
-							ValidateFunc: validation.StringInSlice([]string{"IP_V4", "IP_V6"}, false),
+							ValidateFunc: validation.StringInSlice([]string{"IP_V4", "IP_V6", "DOMAIN"}, false),
 							Description:  "The type of route. Valid values are `IP_V4`, `IP_V6`, and `DOMAIN`.",
 						},
 						"description": {
@@ -75,9 +88,10 @@ func resourceNetwork() *schema.Resource {
 							Description: "The default route description.",
 						},
 						"subnet": {
-							Type:        schema.TypeString,
-							Required:    true,
-							Description: "The target value of the default route.",
+							Type:             schema.TypeString,
+							Required:         true,
+							DiffSuppressFunc: suppressEquivalentCIDRs,
+							Description:      "The target value of the default route: a subnet for `IP_V4` and `IP_V6` routes, or a fully qualified domain name such as `example.com` or `*.example.com` for `DOMAIN` routes.",
 						},
 						"id": {
 							Type:        schema.TypeString,
@@ -88,10 +102,13 @@ func resourceNetwork() *schema.Resource {
 				},
 			},
 			"default_connector": {
-				Type:        schema.TypeList,
-				Required:    true,
-				MaxItems:    1,
-				Description: "The default connector of this network.",
+				Type:         schema.TypeList,
+				Optional:     true,
+				Computed:     true,
+				MaxItems:     1,
+				ExactlyOneOf: []string{"default_connector", "connector"},
+				Deprecated:   "Use `connector` blocks instead. To migrate, replace `default_connector` with a `connector` block of the same name and region; the connector is kept.",
+				Description:  "The default connector of this network.",
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
 						"id": {
@@ -140,23 +157,107 @@ func resourceNetwork() *schema.Resource {
 							Computed:    true,
 							Description: "OpenVPN profile of the connector.",
 						},
+						"connection_status": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Whether the connector is connected to Cloud Connexa, such as `online` or `offline`.",
+						},
 					},
 				},
 			},
+			"connector": {
+				Type:         schema.TypeSet,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"default_connector", "connector"},
+				Set:          networkConnectorHash,
+				Description:  "The set of connectors of this network. Can be defined more than once to run connectors in several regions. When `default_connector` is used instead, lists all the connectors of the network.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The connector id.",
+						},
+						"name": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "Name of the connector.",
+						},
+						"vpn_region_id": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "The id of the region where the connector will be deployed.",
+						},
+						"network_item_type": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The network object type. This typically will be set to `NETWORK`.",
+						},
+						"network_item_id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The parent network id.",
+						},
+						"ip_v4_address": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The IPV4 address of the connector.",
+						},
+						"ip_v6_address": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The IPV6 address of the connector.",
+						},
+						"profile": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "OpenVPN profile of the connector.",
+						},
+						"connection_status": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Whether the connector is connected to Cloud Connexa, such as `online` or `offline`.",
+						},
+						"wait_for_online": waitForOnlineSchema(),
+					},
+				},
+			},
+			"deletion_protection": deletionProtectionSchema(),
 		},
 	}
 }
 
+// networkConnectorHash identifies a connector of a network by its name and region, so
+// that changing either replaces the connector.
+func networkConnectorHash(i interface{}) int {
+	connector := i.(map[string]interface{})
+	h := fnv.New32a()
+	h.Write([]byte(connector["name"].(string)))
+	h.Write([]byte{0})
+	h.Write([]byte(connector["vpn_region_id"].(string)))
+	return int(h.Sum32())
+}
+
 func resourceNetworkCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*cloudconnexa.Client)
+	c := m.(*apiClient).withContext(ctx)
 	var diags diag.Diagnostics
-	configConnector := d.Get("default_connector").([]interface{})[0].(map[string]interface{})
-	connectors := []cloudconnexa.NetworkConnector{
-		{
+	var connectors []cloudconnexa.NetworkConnector
+	defaultConnector := d.Get("default_connector").([]interface{})
+	if len(defaultConnector) > 0 {
+		configConnector := defaultConnector[0].(map[string]interface{})
+		connectors = append(connectors, cloudconnexa.NetworkConnector{
 			Name:        configConnector["name"].(string),
 			VpnRegionId: configConnector["vpn_region_id"].(string),
 			Description: configConnector["description"].(string),
-		},
+		})
+	} else {
+		for _, c := range d.Get("connector").(*schema.Set).List() {
+			connectors = append(connectors, cloudconnexa.NetworkConnector{
+				Name:        c.(map[string]interface{})["name"].(string),
+				VpnRegionId: c.(map[string]interface{})["vpn_region_id"].(string),
+			})
+		}
 	}
 	n := cloudconnexa.Network{
 		Name:           d.Get("name").(string),
@@ -167,90 +268,116 @@ func resourceNetworkCreate(ctx context.Context, d *schema.ResourceData, m interf
 	}
 	network, err := c.Networks.Create(n)
 	if err != nil {
-		return append(diags, diag.FromErr(err)...)
+		return append(diags, diagFromErr(ctx, err)...)
 	}
 	d.SetId(network.Id)
 	configRoute := d.Get("default_route").([]interface{})[0].(map[string]interface{})
-	defaultRoute, err := c.Routes.Create(network.Id, cloudconnexa.Route{
-		Type:        configRoute["type"].(string),
-		Description: configRoute["description"].(string),
-		Subnet:      configRoute["subnet"].(string),
-	})
+	defaultRoute, err := createRoute(c, network.Id, configRoute["subnet"].(string), configRoute["description"].(string))
 	if err != nil {
-		return append(diags, diag.FromErr(err)...)
+		return append(diags, diagFromErr(ctx, err)...)
 	}
 	defaultRouteWithIdSlice := make([]map[string]interface{}, 1)
 	defaultRouteWithIdSlice[0] = map[string]interface{}{
 		"id":          defaultRoute.Id,
 		"description": defaultRoute.Description,
 		"type":        defaultRoute.Type,
-		"subnet":      defaultRoute.Subnet,
+		"subnet":      defaultRoute.value(),
 	}
 	d.Set("default_route", defaultRouteWithIdSlice)
-	connectorsList := make([]interface{}, 1)
-	connector := make(map[string]interface{})
-	connector["id"] = network.Connectors[0].Id
-	connector["name"] = network.Connectors[0].Name
-	connector["network_item_id"] = network.Connectors[0].NetworkItemId
-	connector["network_item_type"] = network.Connectors[0].NetworkItemType
-	connector["vpn_region_id"] = network.Connectors[0].VpnRegionId
-	connector["ip_v4_address"] = network.Connectors[0].IPv4Address
-	connector["ip_v6_address"] = network.Connectors[0].IPv6Address
-	client := m.(*cloudconnexa.Client)
-	profile, err := client.Connectors.GetProfile(network.Connectors[0].Id)
-	if err != nil {
-		return append(diags, diag.FromErr(err)...)
+	if len(defaultConnector) > 0 {
+		connectorsList := make([]interface{}, 1)
+		connector := make(map[string]interface{})
+		connector["id"] = network.Connectors[0].Id
+		connector["name"] = network.Connectors[0].Name
+		connector["network_item_id"] = network.Connectors[0].NetworkItemId
+		connector["network_item_type"] = network.Connectors[0].NetworkItemType
+		connector["vpn_region_id"] = network.Connectors[0].VpnRegionId
+		connector["ip_v4_address"] = network.Connectors[0].IPv4Address
+		connector["ip_v6_address"] = network.Connectors[0].IPv6Address
+		profile, err := c.Connectors.GetProfile(network.Connectors[0].Id)
+		if err != nil {
+			return append(diags, diagFromErr(ctx, err)...)
+		}
+		connector["profile"] = profile
+		connectorsList[0] = connector
+		err = d.Set("default_connector", connectorsList)
+		if err != nil {
+			return append(diags, diagFromErr(ctx, err)...)
+		}
 	}
-	connector["profile"] = profile
-	connectorsList[0] = connector
-	err = d.Set("default_connector", connectorsList)
+	networkConnectors, err := c.Connectors.GetByNetworkID(network.Id)
 	if err != nil {
-		return append(diags, diag.FromErr(err)...)
+		return append(diags, diagFromErr(ctx, err)...)
+	}
+	if diagnostics := setConnectorsList(ctx, d, c, networkConnectors); diagnostics != nil {
+		return append(diags, diagnostics...)
+	}
+	if waitFor := connectorsToWaitFor(d.Get("connector").(*schema.Set), networkConnectors); len(waitFor) > 0 {
+		if err := waitForConnectorsOnline(ctx, c, waitFor); err != nil {
+			return append(diags, diagFromErr(ctx, err)...)
+		}
+		return append(diags, resourceNetworkRead(ctx, d, m)...)
 	}
 	return append(diags, diag.Diagnostic{
 		Severity: diag.Warning,
-		Summary:  "The default connector for this network needs to be set up manually",
-		Detail:   "Terraform only creates the Cloud Connexa default connector object for this network, but additional manual steps are required to associate a host in your infrastructure with this connector. Go to https://openvpn.net/cloud-docs/connector/ for more information.",
+		Summary:  "The connectors for this network need to be set up manually",
+		Detail:   "Terraform only creates the Cloud Connexa connector objects for this network, but additional manual steps are required to associate a host in your infrastructure with this connector. Go to https://openvpn.net/cloud-docs/connector/ for more information.",
 	})
 }
 
 func resourceNetworkRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*cloudconnexa.Client)
+	c := m.(*apiClient).withContext(ctx)
 	var diags diag.Diagnostics
 	network, err := c.Networks.Get(d.Id())
 	if err != nil {
-		return append(diags, diag.FromErr(err)...)
+		return append(diags, diagFromErr(ctx, err)...)
 	}
 	if network == nil {
-		d.SetId("")
-		return diags
+		return append(diags, removeFromState(d, "network")...)
 	}
 	d.Set("name", network.Name)
 	d.Set("description", network.Description)
 	d.Set("egress", network.Egress)
 	d.Set("internet_access", network.InternetAccess)
 	d.Set("system_subnets", network.SystemSubnets)
-	if len(d.Get("default_connector").([]interface{})) > 0 {
+	networkConnectors, err := c.Connectors.GetByNetworkID(network.Id)
+	if err != nil {
+		return append(diags, diagFromErr(ctx, err)...)
+	}
+	if len(d.Get("default_connector").([]interface{})) > 0 && !defaultConnectorRemoved(d) {
 		configConnector := d.Get("default_connector").([]interface{})[0].(map[string]interface{})
 		connectorName := configConnector["name"].(string)
-		networkConnectors, err := c.Connectors.GetByNetworkID(network.Id)
+		retrievedConnector, err := getConnectorSlice(c, networkConnectors, network.Id, connectorName)
 		if err != nil {
-			return append(diags, diag.FromErr(err)...)
+			return append(diags, diagFromErr(ctx, err)...)
 		}
-		retrievedConnector, err := getConnectorSlice(networkConnectors, network.Id, connectorName, m)
-		if err != nil {
-			return append(diags, diag.FromErr(err)...)
+		// cloudconnexa.Connector has no description; the network's connectors have it.
+		for _, connector := range retrievedConnector {
+			connector := connector.(map[string]interface{})
+			for _, nc := range network.Connectors {
+				if nc.Id == connector["id"] {
+					connector["description"] = nc.Description
+				}
+			}
 		}
 		err = d.Set("default_connector", retrievedConnector)
 		if err != nil {
-			return append(diags, diag.FromErr(err)...)
+			return append(diags, diagFromErr(ctx, err)...)
 		}
+	} else {
+		// Record the absence of a default connector, which would otherwise be planned
+		// as unknown on every run since the attribute is computed, or stay in the
+		// state after being replaced by `connector` blocks.
+		d.Set("default_connector", nil)
+	}
+	if diagnostics := setConnectorsList(ctx, d, c, networkConnectors); diagnostics != nil {
+		return append(diags, diagnostics...)
 	}
 	if len(d.Get("default_route").([]interface{})) > 0 {
 		configRoute := d.Get("default_route").([]interface{})[0].(map[string]interface{})
-		route, err := c.Routes.GetNetworkRoute(d.Id(), configRoute["id"].(string))
+		route, err := getNetworkRoute(c, d.Id(), configRoute["id"].(string))
 		if err != nil {
-			return append(diags, diag.FromErr(err)...)
+			return append(diags, diagFromErr(ctx, err)...)
 		}
 		if route == nil {
 			d.Set("default_route", []map[string]interface{}{})
@@ -260,11 +387,9 @@ func resourceNetworkRead(ctx context.Context, d *schema.ResourceData, m interfac
 					"id":          configRoute["id"].(string),
 					"type":        route.Type,
 					"description": route.Description,
+					"subnet":      route.value(),
 				},
 			}
-			if route.Type == "IP_V4" || route.Type == "IP_V6" {
-				defaultRoute[0]["subnet"] = route.Subnet
-			}
 			d.Set("default_route", defaultRoute)
 		}
 	}
@@ -272,24 +397,32 @@ func resourceNetworkRead(ctx context.Context, d *schema.ResourceData, m interfac
 }
 
 func resourceNetworkUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*cloudconnexa.Client)
+	c := m.(*apiClient).withContext(ctx)
 	var diags diag.Diagnostics
 	if d.HasChange("default_connector") {
 		old, new := d.GetChange("default_connector")
 		oldSlice := old.([]interface{})
 		newSlice := new.([]interface{})
 		if len(oldSlice) == 0 && len(newSlice) == 1 {
-			// This happens when importing the resource
-			newConnector := cloudconnexa.Connector{
-				Name:            newSlice[0].(map[string]interface{})["name"].(string),
-				VpnRegionId:     newSlice[0].(map[string]interface{})["vpn_region_id"].(string),
-				NetworkItemType: "NETWORK",
-			}
-			_, err := c.Connectors.Create(newConnector, d.Id())
+			// This happens when a network with several connectors has been imported.
+			// Adopt the connector of the same name and region if there is one.
+			newMap := newSlice[0].(map[string]interface{})
+			existing, err := findNetworkConnector(c, d.Id(), newMap["name"].(string), newMap["vpn_region_id"].(string))
 			if err != nil {
-				return append(diags, diag.FromErr(err)...)
+				return append(diags, diagFromErr(ctx, err)...)
 			}
-		} else {
+			if existing == nil {
+				newConnector := cloudconnexa.Connector{
+					Name:            newMap["name"].(string),
+					VpnRegionId:     newMap["vpn_region_id"].(string),
+					NetworkItemType: "NETWORK",
+				}
+				_, err := c.Connectors.Create(newConnector, d.Id())
+				if err != nil {
+					return append(diags, diagFromErr(ctx, err)...)
+				}
+			}
+		} else if len(oldSlice) == 1 && len(newSlice) == 1 {
 			oldMap := oldSlice[0].(map[string]interface{})
 			newMap := newSlice[0].(map[string]interface{})
 			if oldMap["name"].(string) != newMap["name"].(string) || oldMap["vpn_region_id"].(string) != newMap["vpn_region_id"].(string) {
@@ -300,36 +433,57 @@ func resourceNetworkUpdate(ctx context.Context, d *schema.ResourceData, m interf
 				}
 				_, err := c.Connectors.Create(newConnector, d.Id())
 				if err != nil {
-					return append(diags, diag.FromErr(err)...)
+					return append(diags, diagFromErr(ctx, err)...)
 				}
 				if len(oldMap["id"].(string)) > 0 {
 					// This can sometimes happen when importing the resource
 					err = c.Connectors.Delete(oldMap["id"].(string), d.Id(), oldMap["network_item_type"].(string))
 					if err != nil {
-						return append(diags, diag.FromErr(err)...)
+						return append(diags, diagFromErr(ctx, err)...)
 					}
 				}
 			}
 		}
 	}
+	var created []cloudconnexa.Connector
+	if d.HasChange("connector") {
+		old, new := d.GetChange("connector")
+		oldSet := old.(*schema.Set)
+		newSet := new.(*schema.Set)
+		// Add the new connectors first so that the network is never left without one.
+		for _, n := range newSet.List() {
+			if !oldSet.Contains(n) {
+				newConnector := cloudconnexa.Connector{
+					Name:            n.(map[string]interface{})["name"].(string),
+					VpnRegionId:     n.(map[string]interface{})["vpn_region_id"].(string),
+					NetworkItemType: "NETWORK",
+				}
+				connector, err := c.Connectors.Create(newConnector, d.Id())
+				if err != nil {
+					return append(diags, diagFromErr(ctx, err)...)
+				}
+				created = append(created, *connector)
+			}
+		}
+		for _, o := range oldSet.List() {
+			if !newSet.Contains(o) {
+				err := c.Connectors.Delete(o.(map[string]interface{})["id"].(string), d.Id(), "NETWORK")
+				if err != nil {
+					return append(diags, diagFromErr(ctx, err)...)
+				}
+			}
+		}
+	}
 	if d.HasChange("default_route") {
 		old, new := d.GetChange("default_route")
 		oldSlice := old.([]interface{})
 		newSlice := new.([]interface{})
 		if len(oldSlice) == 0 && len(newSlice) == 1 {
-			// This happens when importing the resource
+			// This happens when a network without routes has been imported.
 			newMap := newSlice[0].(map[string]interface{})
-			routeType := newMap["type"]
-			routeDesc := newMap["description"]
-			routeSubnet := newMap["subnet"]
-			route := cloudconnexa.Route{
-				Type:        routeType.(string),
-				Description: routeDesc.(string),
-				Subnet:      routeSubnet.(string),
-			}
-			defaultRoute, err := c.Routes.Create(d.Id(), route)
+			defaultRoute, err := createRoute(c, d.Id(), newMap["subnet"].(string), newMap["description"].(string))
 			if err != nil {
-				return append(diags, diag.FromErr(err)...)
+				return append(diags, diagFromErr(ctx, err)...)
 			}
 			defaultRouteWithIdSlice := make([]map[string]interface{}, 1)
 			defaultRouteWithIdSlice[0] = map[string]interface{}{
@@ -338,23 +492,13 @@ func resourceNetworkUpdate(ctx context.Context, d *schema.ResourceData, m interf
 			}
 			err = d.Set("default_route", defaultRouteWithIdSlice)
 			if err != nil {
-				diags = append(diags, diag.FromErr(err)...)
+				diags = append(diags, diagFromErr(ctx, err)...)
 			}
 		} else {
 			newMap := newSlice[0].(map[string]interface{})
-			routeId := newMap["id"]
-			routeType := newMap["type"]
-			routeDesc := newMap["description"]
-			routeSubnet := newMap["subnet"]
-			route := cloudconnexa.Route{
-				Id:          routeId.(string),
-				Type:        routeType.(string),
-				Description: routeDesc.(string),
-				Subnet:      routeSubnet.(string),
-			}
-			err := c.Routes.Update(d.Id(), route)
+			err := updateRoute(c, d.Id(), newMap["id"].(string), newMap["subnet"].(string), newMap["description"].(string))
 			if err != nil {
-				diags = append(diags, diag.FromErr(err)...)
+				diags = append(diags, diagFromErr(ctx, err)...)
 			}
 		}
 	}
@@ -371,19 +515,90 @@ func resourceNetworkUpdate(ctx context.Context, d *schema.ResourceData, m interf
 			InternetAccess: newAccess.(string),
 		})
 		if err != nil {
-			return append(diags, diag.FromErr(err)...)
+			return append(diags, diagFromErr(ctx, err)...)
 		}
 	}
+	if err := waitForConnectorsOnline(ctx, c, connectorsToWaitFor(d.Get("connector").(*schema.Set), created)); err != nil {
+		return append(diags, diagFromErr(ctx, err)...)
+	}
 	return append(diags, resourceNetworkRead(ctx, d, m)...)
 }
 
+// defaultConnectorRemoved reports whether `default_connector` is missing from the
+// configuration, as after migrating to `connector` blocks. Being computed, it would
+// otherwise stay in the state. The configuration is not known when refreshing.
+func defaultConnectorRemoved(d *schema.ResourceData) bool {
+	config := d.GetRawConfig()
+	if config.IsNull() || !config.IsKnown() {
+		return false
+	}
+	defaultConnector := config.GetAttr("default_connector")
+	return defaultConnector.IsKnown() && (defaultConnector.IsNull() || defaultConnector.LengthInt() == 0)
+}
+
+// resourceNetworkImport records the existing connector and route of the network as
+// its defaults, so that importing a network does not create new ones; the read that
+// follows the import fills in their attributes. The API does not order the routes, so
+// the one whose subnet sorts first becomes the default route, and the same route is
+// picked by every import. The default connector is only set when the network has a
+// single connector; `connector` lists them all.
+func resourceNetworkImport(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	c := m.(*apiClient).withContext(ctx)
+	connectors, err := c.Connectors.GetByNetworkID(d.Id())
+	if err != nil {
+		return nil, err
+	}
+	if len(connectors) == 1 {
+		d.Set("default_connector", []map[string]interface{}{
+			{
+				"id":            connectors[0].Id,
+				"name":          connectors[0].Name,
+				"vpn_region_id": connectors[0].VpnRegionId,
+			},
+		})
+	}
+	routes, err := c.Routes.List(d.Id())
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Subnet != routes[j].Subnet {
+			return routes[i].Subnet < routes[j].Subnet
+		}
+		return routes[i].Id < routes[j].Id
+	})
+	if len(routes) > 0 {
+		d.Set("default_route", []map[string]interface{}{
+			{
+				"id": routes[0].Id,
+			},
+		})
+	}
+	return []*schema.ResourceData{d}, nil
+}
+
+// findNetworkConnector returns the connector of the network with the given name and
+// region, or nil if there is none.
+func findNetworkConnector(c *cloudconnexa.Client, networkId, name, vpnRegionId string) (*cloudconnexa.Connector, error) {
+	connectors, err := c.Connectors.GetByNetworkID(networkId)
+	if err != nil {
+		return nil, err
+	}
+	for _, connector := range connectors {
+		if connector.Name == name && connector.VpnRegionId == vpnRegionId {
+			return &connector, nil
+		}
+	}
+	return nil, nil
+}
+
 func resourceNetworkDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*cloudconnexa.Client)
+	c := m.(*apiClient).withContext(ctx)
 	var diags diag.Diagnostics
 	networkId := d.Id()
 	err := c.Networks.Delete(networkId)
 	if err != nil {
-		return append(diags, diag.FromErr(err)...)
+		return append(diags, diagFromErr(ctx, err)...)
 	}
 	return diags
 }
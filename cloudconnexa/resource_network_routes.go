@@ -0,0 +1,304 @@
+package cloudconnexa
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"net"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/openvpn/cloudconnexa-go-client/v2/cloudconnexa"
+)
+
+func resourceNetworkRoutes() *schema.Resource {
+	return &schema.Resource{
+		Description:   "Use `cloudconnexa_network_routes` to manage the routes of an Cloud Connexa network as a whole.",
+		CreateContext: resourceNetworkRoutesCreate,
+		ReadContext:   resourceNetworkRoutesRead,
+		UpdateContext: resourceNetworkRoutesUpdate,
+		DeleteContext: resourceNetworkRoutesDelete,
+		CustomizeDiff: customizeDiffNetworkRoutes,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceNetworkRoutesImport,
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(defaultTimeout),
+			Read:   schema.DefaultTimeout(defaultTimeout),
+			Update: schema.DefaultTimeout(defaultTimeout),
+			Delete: schema.DefaultTimeout(defaultTimeout),
+		},
+		Schema: map[string]*schema.Schema{
+			"network_item_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The id of the network whose routes are managed.",
+			},
+			"exclusive": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Whether the routes of the network that are not declared in `route` are deleted, including the routes created outside Terraform and the default route of the network. Defaults to `false`, in which case only the routes that were declared before are deleted.",
+			},
+			"route": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Set:         networkRouteHash,
+				Description: "The routes of the network. Can be defined more than once. Routes are identified by their value, so changing the description of a route updates it in place.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The ID of the route.",
+						},
+						"type": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Computed:     true,
+							ValidateFunc: validation.StringInSlice([]string{"IP_V4", "IP_V6", "DOMAIN"}, false),
+							Description:  "The type of route. Valid values are `IP_V4`, `IP_V6`, and `DOMAIN`. Inferred from `subnet` when not set.",
+						},
+						"subnet": {
+							Type:             schema.TypeString,
+							Required:         true,
+							DiffSuppressFunc: suppressEquivalentCIDRs,
+							Description:      "The target value of the route: a subnet for `IP_V4` and `IP_V6` routes, or a fully qualified domain name such as `example.com` or `*.example.com` for `DOMAIN` routes.",
+						},
+						"description": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Default:     "Managed by Terraform",
+							Description: "The description of the route. Defaults to `Managed by Terraform`.",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// routeKey identifies a route of a network by its value, regardless of the way the
+// value is written.
+func routeKey(value string) string {
+	return canonicalCIDR(strings.ToLower(value))
+}
+
+// networkRouteHash identifies a route by its value, so that changing the value replaces
+// the route while changing its description updates it.
+func networkRouteHash(i interface{}) int {
+	route := i.(map[string]interface{})
+	h := fnv.New32a()
+	h.Write([]byte(routeKey(route["subnet"].(string))))
+	return int(h.Sum32())
+}
+
+// routeTypeOf infers the type of a route from its value, as the API does.
+func routeTypeOf(value string) string {
+	if ip, _, err := net.ParseCIDR(value); err == nil {
+		if ip.To4() != nil {
+			return "IP_V4"
+		}
+		return "IP_V6"
+	}
+	return "DOMAIN"
+}
+
+func flattenNetworkRoute(r networkRoute) map[string]interface{} {
+	return map[string]interface{}{
+		"id":          r.Id,
+		"type":        r.Type,
+		"subnet":      r.value(),
+		"description": r.Description,
+	}
+}
+
+func customizeDiffNetworkRoutes(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if !d.NewValueKnown("route") {
+		return nil
+	}
+	var errs []error
+	for _, v := range d.Get("route").(*schema.Set).List() {
+		route := v.(map[string]interface{})
+		value := route["subnet"].(string)
+		routeType := route["type"].(string)
+		if routeType == "" {
+			routeType = routeTypeOf(value)
+		}
+		if err := validateRouteValue(routeType, value); err != nil {
+			errs = append(errs, fmt.Errorf("route: %w", err))
+		}
+	}
+	if len(errs) > 0 || !d.HasChange("route") {
+		return errors.Join(errs...)
+	}
+
+	old, new := d.GetChange("route")
+	existing := map[string]bool{}
+	for _, v := range old.(*schema.Set).List() {
+		existing[routeKey(v.(map[string]interface{})["subnet"].(string))] = true
+	}
+	var added []string
+	for _, v := range new.(*schema.Set).List() {
+		value := v.(map[string]interface{})["subnet"].(string)
+		if !existing[routeKey(value)] {
+			added = append(added, value)
+		}
+	}
+	// The routes of the network are replaced as a whole, so they may overlap each other.
+	networkItemId := d.Get("network_item_id").(string)
+	return checkSubnetOverlaps(ctx, m, "route", added, func(r routedSubnet) bool {
+		return r.networkId != "" && r.networkId == networkItemId
+	})
+}
+
+func resourceNetworkRoutesCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*apiClient).withContext(ctx)
+	var diags diag.Diagnostics
+	networkItemId := d.Get("network_item_id").(string)
+	if err := applyNetworkRoutes(c, d, networkItemId); err != nil {
+		return append(diags, diagFromErr(ctx, err)...)
+	}
+	d.SetId(networkItemId)
+	return append(diags, resourceNetworkRoutesRead(ctx, d, m)...)
+}
+
+// resourceNetworkRoutesRead only reads the routes recorded in the state, unless
+// `exclusive` is true, in which case routes added outside Terraform show up as changes
+// to be reverted.
+func resourceNetworkRoutesRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*apiClient).withContext(ctx)
+	var diags diag.Diagnostics
+	routes, err := listRoutes(c, d.Id())
+	if isNotFound(err) {
+		return append(diags, removeFromState(d, "network")...)
+	}
+	if err != nil {
+		return append(diags, diagFromErr(ctx, err)...)
+	}
+	managed := map[string]bool{}
+	for _, v := range d.Get("route").(*schema.Set).List() {
+		managed[v.(map[string]interface{})["id"].(string)] = true
+	}
+	exclusive := d.Get("exclusive").(bool)
+	var configRoutes []interface{}
+	for _, r := range routes {
+		if exclusive || managed[r.Id] {
+			configRoutes = append(configRoutes, flattenNetworkRoute(r))
+		}
+	}
+	d.Set("network_item_id", d.Id())
+	if err := d.Set("route", configRoutes); err != nil {
+		return append(diags, diagFromErr(ctx, err)...)
+	}
+	return diags
+}
+
+func resourceNetworkRoutesUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*apiClient).withContext(ctx)
+	var diags diag.Diagnostics
+	if d.HasChanges("route", "exclusive") {
+		if err := applyNetworkRoutes(c, d, d.Id()); err != nil {
+			return append(diags, diagFromErr(ctx, err)...)
+		}
+	}
+	return append(diags, resourceNetworkRoutesRead(ctx, d, m)...)
+}
+
+func resourceNetworkRoutesDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*apiClient).withContext(ctx)
+	var diags diag.Diagnostics
+	for _, v := range d.Get("route").(*schema.Set).List() {
+		err := c.Routes.Delete(d.Id(), v.(map[string]interface{})["id"].(string))
+		if err != nil && !isNotFound(err) {
+			return append(diags, diagFromErr(ctx, err)...)
+		}
+	}
+	return diags
+}
+
+// resourceNetworkRoutesImport records every route of the network in the state, so that
+// the routes missing from the configuration are planned for deletion.
+func resourceNetworkRoutesImport(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	c := m.(*apiClient).withContext(ctx)
+	routes, err := listRoutes(c, d.Id())
+	if err != nil {
+		return nil, err
+	}
+	var configRoutes []interface{}
+	for _, r := range routes {
+		configRoutes = append(configRoutes, flattenNetworkRoute(r))
+	}
+	d.Set("route", configRoutes)
+	d.Set("exclusive", false)
+	return []*schema.ResourceData{d}, nil
+}
+
+// applyNetworkRoutes makes the routes of the network match the configuration. Declared
+// routes that already exist, whether they are recorded in the state or not, are kept
+// and their description is updated. The routes are created before the removed ones are
+// deleted, so that the traffic they carry is never left without a route.
+func applyNetworkRoutes(c *cloudconnexa.Client, d *schema.ResourceData, networkItemId string) error {
+	existing, err := listRoutes(c, networkItemId)
+	if err != nil {
+		return err
+	}
+	existingByKey := map[string]networkRoute{}
+	for _, r := range existing {
+		if _, ok := existingByKey[routeKey(r.value())]; !ok {
+			existingByKey[routeKey(r.value())] = r
+		}
+	}
+
+	old, new := d.GetChange("route")
+	declared := map[string]bool{}
+	var configRoutes []interface{}
+	for _, v := range new.(*schema.Set).List() {
+		route := v.(map[string]interface{})
+		value := route["subnet"].(string)
+		description := route["description"].(string)
+		declared[routeKey(value)] = true
+		if r, ok := existingByKey[routeKey(value)]; ok {
+			if r.Description != description {
+				if err := updateRoute(c, networkItemId, r.Id, r.value(), description); err != nil {
+					return err
+				}
+				r.Description = description
+			}
+			configRoutes = append(configRoutes, flattenNetworkRoute(r))
+			continue
+		}
+		r, err := createRoute(c, networkItemId, value, description)
+		if err != nil {
+			return err
+		}
+		configRoutes = append(configRoutes, flattenNetworkRoute(*r))
+	}
+	// Record the routes that have been created so far, even if deleting the others fails.
+	d.Set("route", configRoutes)
+
+	removed := map[string]bool{}
+	for _, v := range old.(*schema.Set).List() {
+		route := v.(map[string]interface{})
+		if !declared[routeKey(route["subnet"].(string))] {
+			removed[route["id"].(string)] = true
+		}
+	}
+	if d.Get("exclusive").(bool) {
+		for _, r := range existing {
+			if !declared[routeKey(r.value())] {
+				removed[r.Id] = true
+			}
+		}
+	}
+	for id := range removed {
+		if err := c.Routes.Delete(networkItemId, id); err != nil && !isNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,209 @@
+package cloudconnexa
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/OpenVPN/terraform-provider-openvpn-cloud/internal/fakeapi"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/openvpn/cloudconnexa-go-client/v2/cloudconnexa"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccCloudConnexaNetworkRoutes_basic(t *testing.T) {
+	random := testAccUseCassette(t)
+	rName := random.RandomWithPrefix("test-network-routes")
+	resourceName := "cloudconnexa_network_routes.test"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckCloudConnexaNetworkDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCloudConnexaNetworkRoutesConfig(rName, false, "10.2.0.0/16", "app.example.com"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "route.#", "2"),
+					resource.TestCheckTypeSetElemNestedAttrs(resourceName, "route.*", map[string]string{
+						"subnet": "app.example.com",
+						"type":   "DOMAIN",
+					}),
+				),
+			},
+			{
+				// The default route of the network must be declared once the routes are exclusive.
+				Config: testAccCloudConnexaNetworkRoutesConfig(rName, true, "10.1.0.0/16", "10.3.0.0/16"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "route.#", "2"),
+					resource.TestCheckTypeSetElemNestedAttrs(resourceName, "route.*", map[string]string{
+						"subnet": "10.3.0.0/16",
+						"type":   "IP_V4",
+					}),
+				),
+			},
+			{
+				ResourceName:            resourceName,
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"exclusive"},
+			},
+		},
+	})
+}
+
+func testAccCloudConnexaNetworkRoutesConfig(rName string, exclusive bool, values ...string) string {
+	var routes string
+	for _, value := range values {
+		routes += fmt.Sprintf(`
+  route {
+    subnet = "%s"
+  }`, value)
+	}
+	return fmt.Sprintf(`%[1]s
+resource "cloudconnexa_network" "test" {
+  name = "%[2]s"
+  connector {
+    name          = "%[2]s"
+    vpn_region_id = "fi-hel"
+  }
+  default_route {
+    subnet = "10.1.0.0/16"
+  }
+}
+
+resource "cloudconnexa_network_routes" "test" {
+  network_item_id = cloudconnexa_network.test.id
+  exclusive       = %[3]t%[4]s
+}
+`, testAccProviderConfig(), rName, exclusive, routes)
+}
+
+func TestResourceNetworkRoutes(t *testing.T) {
+	server := fakeapi.NewServer()
+	defer server.Close()
+	p := newTestProvider(t, server)
+	meta := p.Meta().(*apiClient)
+	ctx := context.Background()
+	c := meta.client
+
+	network := testApply(t, p.ResourcesMap["cloudconnexa_network"], nil, map[string]interface{}{
+		"name":          "network",
+		"connector":     []interface{}{map[string]interface{}{"name": "west", "vpn_region_id": "us-west-1"}},
+		"default_route": []interface{}{map[string]interface{}{"subnet": "10.1.0.0/16"}},
+	}, meta)
+	unmanaged, err := c.Routes.Create(network.ID, cloudconnexa.Route{Subnet: "10.9.0.0/16", Description: "added by hand"})
+	require.NoError(t, err)
+
+	r := p.ResourcesMap["cloudconnexa_network_routes"]
+	config := func(exclusive bool, routes ...map[string]interface{}) map[string]interface{} {
+		raw := map[string]interface{}{"network_item_id": network.ID, "exclusive": exclusive}
+		var list []interface{}
+		for _, route := range routes {
+			list = append(list, route)
+		}
+		raw["route"] = list
+		return raw
+	}
+	route := func(value, description string) map[string]interface{} {
+		return map[string]interface{}{"subnet": value, "description": description}
+	}
+	routeIds := func(state *terraform.InstanceState) map[string]string {
+		ids := map[string]string{}
+		for _, v := range r.Data(state).Get("route").(*schema.Set).List() {
+			v := v.(map[string]interface{})
+			ids[v["subnet"].(string)] = v["id"].(string)
+		}
+		return ids
+	}
+	apiRoutes := func() map[string]string {
+		routes, err := listRoutes(c, network.ID)
+		require.NoError(t, err)
+		descriptions := map[string]string{}
+		for _, r := range routes {
+			descriptions[r.value()] = r.Description
+		}
+		return descriptions
+	}
+	assertNoChanges := func(state *terraform.InstanceState, raw map[string]interface{}) *terraform.InstanceState {
+		state, diags := r.RefreshWithoutUpgrade(ctx, state, meta)
+		require.False(t, diags.HasError(), "%v", diags)
+		diff, err := r.Diff(ctx, state, terraform.NewResourceConfigRaw(raw), meta)
+		require.NoError(t, err)
+		assert.Nil(t, diff)
+		return state
+	}
+
+	raw := config(false, route("10.2.0.0/16", "a"), route("app.example.com", "b"))
+	state := testApply(t, r, nil, raw, meta)
+	assert.Equal(t, network.ID, state.ID)
+	state = assertNoChanges(state, raw)
+	created := routeIds(state)
+	assert.Len(t, created, 2)
+	assert.Equal(t, map[string]string{
+		"10.1.0.0/16":     "Managed by Terraform.",
+		"10.9.0.0/16":     "added by hand",
+		"10.2.0.0/16":     "a",
+		"app.example.com": "b",
+	}, apiRoutes())
+
+	// Descriptions are updated in place and removed routes are deleted, but routes that
+	// were never declared are left alone.
+	raw = config(false, route("10.2.0.0/16", "changed"), route("10.3.0.5/24", "c"))
+	state = testApply(t, r, state, raw, meta)
+	state = assertNoChanges(state, raw)
+	updated := routeIds(state)
+	assert.Equal(t, created["10.2.0.0/16"], updated["10.2.0.0/16"])
+	assert.Equal(t, map[string]string{
+		"10.1.0.0/16": "Managed by Terraform.",
+		"10.9.0.0/16": "added by hand",
+		"10.2.0.0/16": "changed",
+		"10.3.0.0/24": "c",
+	}, apiRoutes())
+
+	// Exclusive routes adopt the declared routes that already exist and delete the others.
+	raw = config(true, route("10.1.0.0/16", "Managed by Terraform."), route("10.2.0.0/16", "changed"))
+	state = testApply(t, r, state, raw, meta)
+	state = assertNoChanges(state, raw)
+	assert.Equal(t, map[string]string{
+		"10.1.0.0/16": "Managed by Terraform.",
+		"10.2.0.0/16": "changed",
+	}, apiRoutes())
+	assert.Equal(t, r.Data(state).Get("route").(*schema.Set).Len(), 2)
+	assert.NotContains(t, routeIds(state), unmanaged.Subnet)
+	defaultRouteId := p.ResourcesMap["cloudconnexa_network"].Data(network).Get("default_route.0.id")
+	assert.Equal(t, defaultRouteId, routeIds(state)["10.1.0.0/16"], "the default route must be kept")
+
+	// Routes added outside Terraform show up as changes to be reverted.
+	_, err = c.Routes.Create(network.ID, cloudconnexa.Route{Subnet: "10.8.0.0/16"})
+	require.NoError(t, err)
+	state, diags := r.RefreshWithoutUpgrade(ctx, state, meta)
+	require.False(t, diags.HasError(), "%v", diags)
+	assert.Contains(t, routeIds(state), "10.8.0.0/16")
+	state = testApply(t, r, state, raw, meta)
+	assert.NotContains(t, apiRoutes(), "10.8.0.0/16")
+
+	testImportStateVerify(t, r, network.ID, state, meta, "exclusive")
+
+	diags = r.DeleteContext(ctx, r.Data(state), meta)
+	require.False(t, diags.HasError(), "%v", diags)
+	assert.Empty(t, apiRoutes())
+}
+
+func TestResourceNetworkRoutes_validatesRoutes(t *testing.T) {
+	r := Provider().ResourcesMap["cloudconnexa_network_routes"]
+	_, err := r.Diff(context.Background(), nil, terraform.NewResourceConfigRaw(map[string]interface{}{
+		"network_item_id": "network",
+		"route": []interface{}{
+			map[string]interface{}{"subnet": "10.0.0.0/8"},
+			map[string]interface{}{"subnet": "10.0.0.0/8", "type": "IP_V6"},
+			map[string]interface{}{"subnet": "not a domain"},
+		},
+	}), &apiClient{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `"10.0.0.0/8" is not a valid IPv6 CIDR`)
+	assert.Contains(t, err.Error(), `"not a domain" is not a valid domain`)
+}
@@ -0,0 +1,376 @@
+package cloudconnexa
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/OpenVPN/terraform-provider-openvpn-cloud/internal/fakeapi"
+	ctyjson "github.com/hashicorp/go-cty/cty/json"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/openvpn/cloudconnexa-go-client/v2/cloudconnexa"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccCloudConnexaNetwork_connectors(t *testing.T) {
+	random := testAccUseCassette(t)
+	rName := random.RandomWithPrefix("test-network")
+	resourceName := "cloudconnexa_network.test"
+	var connectorId string
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckCloudConnexaNetworkDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCloudConnexaNetworkConfigDefaultConnector(rName),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "default_connector.#", "1"),
+					resource.TestCheckResourceAttr(resourceName, "connector.#", "1"),
+					resource.TestCheckResourceAttrWith(resourceName, "default_connector.0.id", func(id string) error {
+						connectorId = id
+						return nil
+					}),
+				),
+			},
+			{
+				// Replacing default_connector with the same connector block keeps the connector.
+				Config: testAccCloudConnexaNetworkConfigConnectors(rName, "us-west-1"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "connector.#", "1"),
+					resource.TestCheckResourceAttrWith(resourceName, "connector.0.id", func(id string) error {
+						if id != connectorId {
+							return fmt.Errorf("connector %s was replaced by %s", connectorId, id)
+						}
+						return nil
+					}),
+				),
+			},
+			{
+				// Connectors can be added and removed once migrated.
+				Config: testAccCloudConnexaNetworkConfigConnectors(rName, "us-west-1", "eu-central-1"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "default_connector.#", "0"),
+					resource.TestCheckResourceAttr(resourceName, "connector.#", "2"),
+					testAccCheckCloudConnexaNetworkConnectors(resourceName, 2),
+					resource.TestCheckTypeSetElemNestedAttrs(resourceName, "connector.*", map[string]string{
+						"name":          rName + "-eu-central-1",
+						"vpn_region_id": "eu-central-1",
+					}),
+				),
+			},
+			{
+				Config: testAccCloudConnexaNetworkConfigConnectors(rName, "eu-central-1"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "connector.#", "1"),
+					testAccCheckCloudConnexaNetworkConnectors(resourceName, 1),
+					resource.TestCheckResourceAttrSet(resourceName, "connector.0.ip_v4_address"),
+					resource.TestCheckResourceAttrSet(resourceName, "connector.0.profile"),
+				),
+			},
+			{
+				ResourceName:      resourceName,
+				ImportState:       true,
+				ImportStateVerify: true,
+				// The imported network records its only connector as the default one too.
+				ImportStateVerifyIgnore: []string{"default_connector"},
+			},
+		},
+	})
+}
+
+func testAccCheckCloudConnexaNetworkDestroy(s *terraform.State) error {
+	client := testAccProvider.Meta().(*apiClient).client
+
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "cloudconnexa_network" {
+			continue
+		}
+		network, err := client.Networks.Get(rs.Primary.ID)
+		if err != nil {
+			return err
+		}
+		if network != nil {
+			return fmt.Errorf("network with ID '%s' still exists", rs.Primary.ID)
+		}
+	}
+	return nil
+}
+
+// testAccCheckCloudConnexaNetworkConnectors checks the number of connectors the
+// network has in Cloud Connexa, rather than in the state.
+func testAccCheckCloudConnexaNetworkConnectors(resourceName string, expected int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[resourceName]
+		if !ok {
+			return fmt.Errorf("resource %s not found", resourceName)
+		}
+		client := testAccProvider.Meta().(*apiClient).client
+		connectors, err := client.Connectors.GetByNetworkID(rs.Primary.ID)
+		if err != nil {
+			return err
+		}
+		if len(connectors) != expected {
+			return fmt.Errorf("network %s has %d connectors, expected %d", rs.Primary.ID, len(connectors), expected)
+		}
+		return nil
+	}
+}
+
+func testAccCloudConnexaNetworkConfigDefaultConnector(rName string) string {
+	return fmt.Sprintf(`%[1]s
+resource "cloudconnexa_network" "test" {
+  name = "%[2]s"
+  default_connector {
+    name          = "%[2]s-us-west-1"
+    vpn_region_id = "us-west-1"
+  }
+  default_route {
+    subnet = "10.1.0.0/16"
+  }
+}
+`, testAccProviderConfig(), rName)
+}
+
+func testAccCloudConnexaNetworkConfigConnectors(rName string, regions ...string) string {
+	var connectors string
+	for _, region := range regions {
+		connectors += fmt.Sprintf(`
+  connector {
+    name          = "%[1]s-%[2]s"
+    vpn_region_id = "%[2]s"
+  }`, rName, region)
+	}
+	return fmt.Sprintf(`%[1]s
+resource "cloudconnexa_network" "test" {
+  name = "%[2]s"%[3]s
+  default_route {
+    subnet = "10.1.0.0/16"
+  }
+}
+`, testAccProviderConfig(), rName, connectors)
+}
+
+// testApply plans and applies raw as the configuration of r, starting from state. As
+// Terraform does, it passes the configuration on to the apply, and replaces an object
+// by destroying it before creating the new one.
+func testApply(t *testing.T, r *schema.Resource, state *terraform.InstanceState, raw map[string]interface{}, meta interface{}) *terraform.InstanceState {
+	ctx := context.Background()
+	diff, err := r.Diff(ctx, state, terraform.NewResourceConfigRaw(raw), meta)
+	require.NoError(t, err)
+	if diff == nil {
+		return state
+	}
+	if state != nil && diff.RequiresNew() {
+		_, diags := r.Apply(ctx, state, &terraform.InstanceDiff{Destroy: true}, meta)
+		require.False(t, diags.HasError(), "%v", diags)
+		return testApply(t, r, nil, raw, meta)
+	}
+	encoded, err := json.Marshal(raw)
+	require.NoError(t, err)
+	diff.RawConfig, err = ctyjson.Unmarshal(encoded, r.CoreConfigSchema().ImpliedType())
+	require.NoError(t, err)
+	state, diags := r.Apply(ctx, state, diff, meta)
+	require.False(t, diags.HasError(), "%v", diags)
+	return state
+}
+
+func TestResourceNetwork_connectorsUpdatedInPlace(t *testing.T) {
+	server := fakeapi.NewServer()
+	defer server.Close()
+	p := newTestProvider(t, server)
+	meta := p.Meta().(*apiClient)
+	r := p.ResourcesMap["cloudconnexa_network"]
+	config := func(connectors ...interface{}) map[string]interface{} {
+		return map[string]interface{}{
+			"name":          "network",
+			"connector":     connectors,
+			"default_route": []interface{}{map[string]interface{}{"subnet": "10.1.0.0/16"}},
+		}
+	}
+	connector := func(name, region string) map[string]interface{} {
+		return map[string]interface{}{"name": name, "vpn_region_id": region}
+	}
+	connectorIds := func(state *terraform.InstanceState) map[string]string {
+		d := r.Data(state)
+		ids := map[string]string{}
+		for _, c := range d.Get("connector").(*schema.Set).List() {
+			c := c.(map[string]interface{})
+			assert.NotEmpty(t, c["ip_v4_address"])
+			assert.Contains(t, c["profile"], "fake-private-key-"+c["id"].(string))
+			ids[c["name"].(string)] = c["id"].(string)
+		}
+		return ids
+	}
+
+	state := testApply(t, r, nil, config(connector("west", "us-west-1"), connector("east", "us-east-1")), meta)
+	created := connectorIds(state)
+	require.Len(t, created, 2)
+	networkId := state.ID
+
+	state = testApply(t, r, state, config(connector("west", "us-west-1"), connector("helsinki", "fi-hel")), meta)
+	assert.Equal(t, networkId, state.ID, "the network must be updated in place")
+	updated := connectorIds(state)
+	require.Len(t, updated, 2)
+	assert.Equal(t, created["west"], updated["west"], "unchanged connectors must be kept")
+	assert.NotEmpty(t, updated["helsinki"])
+	remaining, err := meta.client.Connectors.GetByNetworkID(networkId)
+	require.NoError(t, err)
+	assert.Len(t, remaining, 2, "the removed connector must be deleted")
+
+	// Moving a connector to another region replaces it.
+	state = testApply(t, r, state, config(connector("west", "it-mxp"), connector("helsinki", "fi-hel")), meta)
+	moved := connectorIds(state)
+	assert.NotEqual(t, created["west"], moved["west"])
+	westConnector, err := meta.client.Connectors.GetByID(moved["west"])
+	require.NoError(t, err)
+	require.NotNil(t, westConnector)
+	assert.Equal(t, "it-mxp", westConnector.VpnRegionId)
+}
+
+func TestResourceNetwork_migratesFromDefaultConnector(t *testing.T) {
+	server := fakeapi.NewServer()
+	defer server.Close()
+	p := newTestProvider(t, server)
+	meta := p.Meta().(*apiClient)
+	r := p.ResourcesMap["cloudconnexa_network"]
+	route := []interface{}{map[string]interface{}{"subnet": "10.1.0.0/16"}}
+	connector := []interface{}{map[string]interface{}{"name": "west", "vpn_region_id": "us-west-1"}}
+
+	state := testApply(t, r, nil, map[string]interface{}{
+		"name":              "network",
+		"default_connector": connector,
+		"default_route":     route,
+	}, meta)
+	connectorId := r.Data(state).Get("default_connector.0.id").(string)
+	require.NotEmpty(t, connectorId)
+	state, diags := r.RefreshWithoutUpgrade(context.Background(), state, meta)
+	require.False(t, diags.HasError(), "%v", diags)
+	diff, err := r.Diff(context.Background(), state, terraform.NewResourceConfigRaw(map[string]interface{}{
+		"name":              "network",
+		"default_connector": connector,
+		"default_route":     route,
+	}), meta)
+	require.NoError(t, err)
+	assert.Nil(t, diff, "the refreshed network must match its configuration")
+	assert.Equal(t, 1, r.Data(state).Get("connector").(*schema.Set).Len(), "the connectors must be listed alongside default_connector")
+
+	diff, err = r.Diff(context.Background(), state, terraform.NewResourceConfigRaw(map[string]interface{}{
+		"name":          "network",
+		"connector":     connector,
+		"default_route": route,
+	}), meta)
+	require.NoError(t, err)
+	assert.Nil(t, diff, "replacing default_connector with the same connector block must not change anything")
+	state, diags = r.RefreshWithoutUpgrade(context.Background(), state, meta)
+	require.False(t, diags.HasError(), "%v", diags)
+	d := r.Data(state)
+	connectors := d.Get("connector").(*schema.Set).List()
+	require.Len(t, connectors, 1)
+	assert.Equal(t, connectorId, connectors[0].(map[string]interface{})["id"], "the connector must be kept")
+
+	// Once migrated, the connectors are managed by the connector blocks.
+	east := map[string]interface{}{"name": "east", "vpn_region_id": "us-east-1"}
+	state = testApply(t, r, state, map[string]interface{}{
+		"name":          "network",
+		"connector":     append([]interface{}{east}, connector...),
+		"default_route": route,
+	}, meta)
+	assert.Empty(t, r.Data(state).Get("default_connector"), "default_connector must be dropped from the state")
+	assert.Equal(t, 2, r.Data(state).Get("connector").(*schema.Set).Len())
+	networkConnectors, err := meta.client.Connectors.GetByNetworkID(state.ID)
+	require.NoError(t, err)
+	assert.Len(t, networkConnectors, 2, "the added connector must be created")
+
+	state = testApply(t, r, state, map[string]interface{}{
+		"name":          "network",
+		"connector":     []interface{}{east},
+		"default_route": route,
+	}, meta)
+	networkConnectors, err = meta.client.Connectors.GetByNetworkID(state.ID)
+	require.NoError(t, err)
+	require.Len(t, networkConnectors, 1, "the removed connector must be deleted")
+	assert.Equal(t, "east", networkConnectors[0].Name)
+	state, diags = r.RefreshWithoutUpgrade(context.Background(), state, meta)
+	require.False(t, diags.HasError(), "%v", diags)
+	assert.Empty(t, r.Data(state).Get("default_connector"))
+	assert.Equal(t, 1, r.Data(state).Get("connector").(*schema.Set).Len())
+}
+
+func TestResourceNetwork_importIsLossless(t *testing.T) {
+	server := fakeapi.NewServer()
+	defer server.Close()
+	p := newTestProvider(t, server)
+	meta := p.Meta().(*apiClient)
+	r := p.ResourcesMap["cloudconnexa_network"]
+	ctx := context.Background()
+
+	network, err := meta.client.Networks.Create(cloudconnexa.Network{
+		Name:           "network",
+		Description:    "Managed by Terraform",
+		Egress:         true,
+		InternetAccess: "LOCAL",
+		Connectors:     []cloudconnexa.NetworkConnector{{Name: "west", VpnRegionId: "us-west-1", Description: "Managed by Terraform."}},
+	})
+	require.NoError(t, err)
+	_, err = meta.client.Routes.Create(network.Id, cloudconnexa.Route{Type: "IP_V4", Subnet: "10.1.0.0/16", Description: "Managed by Terraform."})
+	require.NoError(t, err)
+
+	imported, err := r.Importer.StateContext(ctx, r.Data(&terraform.InstanceState{ID: network.Id}), meta)
+	require.NoError(t, err)
+	require.Len(t, imported, 1)
+	state, diags := r.RefreshWithoutUpgrade(ctx, imported[0].State(), meta)
+	require.False(t, diags.HasError(), "%v", diags)
+
+	route := []interface{}{map[string]interface{}{"subnet": "10.1.0.0/16"}}
+	connector := []interface{}{map[string]interface{}{"name": "west", "vpn_region_id": "us-west-1"}}
+	for name, raw := range map[string]map[string]interface{}{
+		"default_connector": {"name": "network", "default_connector": connector, "default_route": route},
+		"connector":         {"name": "network", "connector": connector, "default_route": route},
+	} {
+		diff, err := r.Diff(ctx, state, terraform.NewResourceConfigRaw(raw), meta)
+		require.NoError(t, err)
+		assert.True(t, diff == nil || diff.Empty(), "a configuration using %s must match the imported network: %v", name, diff)
+	}
+
+	connectors, err := meta.client.Connectors.GetByNetworkID(network.Id)
+	require.NoError(t, err)
+	assert.Len(t, connectors, 1)
+	routes, err := meta.client.Routes.List(network.Id)
+	require.NoError(t, err)
+	assert.Len(t, routes, 1)
+}
+
+func TestResourceNetwork_importPicksTheSameDefaultRoute(t *testing.T) {
+	server := fakeapi.NewServer()
+	defer server.Close()
+	p := newTestProvider(t, server)
+	meta := p.Meta().(*apiClient)
+	r := p.ResourcesMap["cloudconnexa_network"]
+	ctx := context.Background()
+
+	network, err := meta.client.Networks.Create(cloudconnexa.Network{
+		Name:           "network",
+		Egress:         true,
+		InternetAccess: "LOCAL",
+		Connectors:     []cloudconnexa.NetworkConnector{{Name: "west", VpnRegionId: "us-west-1"}},
+	})
+	require.NoError(t, err)
+	// The fake lists the routes in the order they were created.
+	for _, subnet := range []string{"10.2.0.0/16", "10.1.0.0/16", "10.3.0.0/16"} {
+		_, err = meta.client.Routes.Create(network.Id, cloudconnexa.Route{Type: "IP_V4", Subnet: subnet})
+		require.NoError(t, err)
+	}
+
+	imported, err := r.Importer.StateContext(ctx, r.Data(&terraform.InstanceState{ID: network.Id}), meta)
+	require.NoError(t, err)
+	require.Len(t, imported, 1)
+	state, diags := r.RefreshWithoutUpgrade(ctx, imported[0].State(), meta)
+	require.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, "10.1.0.0/16", r.Data(state).Get("default_route.0.subnet"))
+}
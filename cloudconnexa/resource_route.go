@@ -2,10 +2,11 @@ package cloudconnexa
 
 import (
 	"context"
-
-	"github.com/openvpn/cloudconnexa-go-client/v2/cloudconnexa"
+	"fmt"
+	"strings"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
@@ -17,22 +18,30 @@ func resourceRoute() *schema.Resource {
 		UpdateContext: resourceRouteUpdate,
 		ReadContext:   resourceRouteRead,
 		DeleteContext: resourceRouteDelete,
+		CustomizeDiff: customdiff.Sequence(customizeDiffRouteValue("type", "subnet"), customizeDiffRoute),
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: resourceRouteImport,
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(defaultTimeout),
+			Read:   schema.DefaultTimeout(defaultTimeout),
+			Update: schema.DefaultTimeout(defaultTimeout),
+			Delete: schema.DefaultTimeout(defaultTimeout),
 		},
 		Schema: map[string]*schema.Schema{
 			"type": {
 				Type:         schema.TypeString,
 				Required:     true,
 				ForceNew:     true,
-				ValidateFunc: validation.StringInSlice([]string{"IP_V4", "IP_V6"}, false),
+				ValidateFunc: validation.StringInSlice([]string{"IP_V4", "IP_V6", "DOMAIN"}, false),
 				Description:  "The type of route. Valid values are `IP_V4`, `IP_V6`, and `DOMAIN`.",
 			},
 			"subnet": {
-				Type:        schema.TypeString,
-				Required:    true,
-				ForceNew:    true,
-				Description: "The target value of the default route.",
+				Type:             schema.TypeString,
+				Required:         true,
+				ForceNew:         true,
+				DiffSuppressFunc: suppressEquivalentCIDRs,
+				Description:      "The target value of the route: a subnet for `IP_V4` and `IP_V6` routes, or a fully qualified domain name such as `example.com` or `*.example.com` for `DOMAIN` routes.",
 			},
 			"network_item_id": {
 				Type:        schema.TypeString,
@@ -50,51 +59,47 @@ func resourceRoute() *schema.Resource {
 }
 
 func resourceRouteCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*cloudconnexa.Client)
+	c := m.(*apiClient).withContext(ctx)
 	var diags diag.Diagnostics
 	networkItemId := d.Get("network_item_id").(string)
-	routeType := d.Get("type").(string)
 	routeSubnet := d.Get("subnet").(string)
 	descriptionValue := d.Get("description").(string)
-	r := cloudconnexa.Route{
-		Type:        routeType,
-		Subnet:      routeSubnet,
-		Description: descriptionValue,
-	}
-	route, err := c.Routes.Create(networkItemId, r)
+	route, err := createRoute(c, networkItemId, routeSubnet, descriptionValue)
 	if err != nil {
-		return append(diags, diag.FromErr(err)...)
+		return append(diags, diagFromErr(ctx, err)...)
 	}
 	d.SetId(route.Id)
-	if routeType == "IP_V4" || routeType == "IP_V6" {
-		d.Set("subnet", route.Subnet)
-	}
+	d.Set("subnet", route.value())
 	return diags
 }
 
 func resourceRouteRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*cloudconnexa.Client)
+	c := m.(*apiClient).withContext(ctx)
 	var diags diag.Diagnostics
 	routeId := d.Id()
-	r, err := c.Routes.Get(routeId)
-	if err != nil {
-		return append(diags, diag.FromErr(err)...)
-	}
-	if r == nil {
-		d.SetId("")
+	var r *networkRoute
+	var err error
+	if networkItemId := d.Get("network_item_id").(string); networkItemId != "" {
+		r, err = getNetworkRoute(c, networkItemId, routeId)
 	} else {
-		d.Set("type", r.Type)
-		if r.Type == "IP_V4" || r.Type == "IP_V6" {
-			d.Set("subnet", r.Subnet)
-		}
-		d.Set("description", r.Description)
-		d.Set("network_item_id", r.NetworkItemId)
+		// The route has been imported by its ID alone.
+		r, err = getRoute(c, routeId)
+	}
+	if isNotFound(err) || (err == nil && r == nil) {
+		return append(diags, removeFromState(d, "route")...)
 	}
+	if err != nil {
+		return append(diags, diagFromErr(ctx, err)...)
+	}
+	d.Set("type", r.Type)
+	d.Set("subnet", r.value())
+	d.Set("description", r.Description)
+	d.Set("network_item_id", r.NetworkItemId)
 	return diags
 }
 
 func resourceRouteUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*cloudconnexa.Client)
+	c := m.(*apiClient).withContext(ctx)
 	var diags diag.Diagnostics
 	if !d.HasChanges("description", "subnet") {
 		return diags
@@ -103,27 +108,45 @@ func resourceRouteUpdate(ctx context.Context, d *schema.ResourceData, m interfac
 	networkItemId := d.Get("network_item_id").(string)
 	_, description := d.GetChange("description")
 	_, subnet := d.GetChange("subnet")
-	r := cloudconnexa.Route{
-		Id:          d.Id(),
-		Description: description.(string),
-		Subnet:      subnet.(string),
-	}
-
-	err := c.Routes.Update(networkItemId, r)
+	err := updateRoute(c, networkItemId, d.Id(), subnet.(string), description.(string))
 	if err != nil {
-		return append(diags, diag.FromErr(err)...)
+		return append(diags, diagFromErr(ctx, err)...)
 	}
 	return diags
 }
 
 func resourceRouteDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*cloudconnexa.Client)
+	c := m.(*apiClient).withContext(ctx)
 	var diags diag.Diagnostics
 	routeId := d.Id()
 	networkItemId := d.Get("network_item_id").(string)
 	err := c.Routes.Delete(networkItemId, routeId)
 	if err != nil {
-		return append(diags, diag.FromErr(err)...)
+		return append(diags, diagFromErr(ctx, err)...)
 	}
 	return diags
 }
+
+// resourceRouteImport accepts `<network_item_id>/<route_id>` as well as the route ID
+// alone, in which case the read that follows the import looks for the route in every
+// network.
+func resourceRouteImport(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	networkItemId, routeId, ok := strings.Cut(d.Id(), "/")
+	if !ok {
+		return []*schema.ResourceData{d}, nil
+	}
+	if networkItemId == "" || routeId == "" || strings.Contains(routeId, "/") {
+		return nil, fmt.Errorf("unexpected import ID %q, expected <network_item_id>/<route_id> or <route_id>", d.Id())
+	}
+	c := m.(*apiClient).withContext(ctx)
+	r, err := getNetworkRoute(c, networkItemId, routeId)
+	if err != nil {
+		return nil, err
+	}
+	if r == nil {
+		return nil, fmt.Errorf("route %s not found in network %s", routeId, networkItemId)
+	}
+	d.SetId(routeId)
+	d.Set("network_item_id", networkItemId)
+	return []*schema.ResourceData{d}, nil
+}
@@ -1,30 +1,33 @@
 package cloudconnexa
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"testing"
 
+	"github.com/OpenVPN/terraform-provider-openvpn-cloud/internal/fakeapi"
 	"github.com/openvpn/cloudconnexa-go-client/v2/cloudconnexa"
 
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
 func TestAccCloudConnexaRoute_basic(t *testing.T) {
+	random := testAccUseCassette(t)
 	rn := "cloudconnexa_route.test"
-	ip, err := acctest.RandIpAddress("10.0.0.0/8")
+	ip, err := random.RandIpAddress("10.0.0.0/8")
 	require.NoError(t, err)
 	route := cloudconnexa.Route{
-		Description: "test" + acctest.RandString(10),
+		Description: "test" + random.RandString(10),
 		Type:        "IP_V4",
 		Subnet:      ip + "/32",
 	}
 	routeChanged := route
-	routeChanged.Description = acctest.RandStringFromCharSet(10, alphabet)
-	networkRandString := "test" + acctest.RandString(10)
+	routeChanged.Description = random.RandStringFromCharSet(10, alphabet)
+	networkRandString := "test" + random.RandString(10)
 	var routeId string
 
 	check := func(r cloudconnexa.Route) resource.TestCheckFunc {
@@ -55,12 +58,18 @@ func TestAccCloudConnexaRoute_basic(t *testing.T) {
 				ImportStateIdFunc: testAccCloudConnexaRouteImportStateIdFunc(rn),
 				ImportStateVerify: true,
 			},
+			{
+				ResourceName:      rn,
+				ImportState:       true,
+				ImportStateIdFunc: testAccCloudConnexaRouteCompositeImportStateIdFunc(rn),
+				ImportStateVerify: true,
+			},
 		},
 	})
 }
 
 func testAccCheckCloudConnexaRouteDestroy(s *terraform.State) error {
-	client := testAccProvider.Meta().(*cloudconnexa.Client)
+	client := testAccProvider.Meta().(*apiClient).client
 	for _, rs := range s.RootModule().Resources {
 		if rs.Type != "cloudconnexa_route" {
 			continue
@@ -88,7 +97,7 @@ func testAccCheckCloudConnexaRouteExists(n string, routeID *string) resource.Tes
 			return errors.New("no ID is set")
 		}
 
-		client := testAccProvider.Meta().(*cloudconnexa.Client)
+		client := testAccProvider.Meta().(*apiClient).client
 		_, err := client.Routes.Get(rs.Primary.ID)
 		if err != nil {
 			return err
@@ -107,11 +116,18 @@ func testAccCloudConnexaRouteImportStateIdFunc(n string) resource.ImportStateIdF
 	}
 }
 
-func testAccCloudConnexaRouteConfig(r cloudconnexa.Route, networkRandStr string) string {
-	return fmt.Sprintf(`
-provider "cloudconnexa" {
-	base_url = "https://%[1]s.api.openvpn.com"
+func testAccCloudConnexaRouteCompositeImportStateIdFunc(n string) resource.ImportStateIdFunc {
+	return func(s *terraform.State) (string, error) {
+		rs, ok := s.RootModule().Resources[n]
+		if !ok {
+			return "", fmt.Errorf("not found: %s", n)
+		}
+		return rs.Primary.Attributes["network_item_id"] + "/" + rs.Primary.ID, nil
+	}
 }
+
+func testAccCloudConnexaRouteConfig(r cloudconnexa.Route, networkRandStr string) string {
+	return fmt.Sprintf(`%[1]s
 resource "cloudconnexa_network" "test" {
 	name = "%[5]s"
 	default_connector {
@@ -129,5 +145,101 @@ resource "cloudconnexa_route" "test" {
 	subnet          = "%[3]s"
 	type            = "%[4]s"
 }
-`, testCloudID, r.Description, r.Subnet, r.Type, networkRandStr)
+`, testAccProviderConfig(), r.Description, r.Subnet, r.Type, networkRandStr)
+}
+
+func TestValidateRouteValue(t *testing.T) {
+	for _, value := range []string{"example.com", "*.example.com", "app-1.eu.example.co.uk", "xn--bcher-kva.example"} {
+		assert.NoError(t, validateRouteValue("DOMAIN", value), value)
+	}
+	for _, value := range []string{"", "example", "10.0.0.0/8", "app.*.example.com", "*example.com", "-app.example.com", "app_1.example.com", "example.com."} {
+		assert.Error(t, validateRouteValue("DOMAIN", value), value)
+	}
+	assert.NoError(t, validateRouteValue("IP_V4", "10.0.0.5/8"))
+	assert.NoError(t, validateRouteValue("IP_V6", "fd00::1/64"))
+	assert.Error(t, validateRouteValue("IP_V4", "fd00::/64"))
+	assert.Error(t, validateRouteValue("IP_V4", "10.0.0.0"))
+	assert.Error(t, validateRouteValue("IP_V4", "example.com"))
+	assert.Error(t, validateRouteValue("IP_V6", "10.0.0.0/8"))
+}
+
+func TestResourceRoute_domainRoutes(t *testing.T) {
+	server := fakeapi.NewServer()
+	defer server.Close()
+	p := newTestProvider(t, server)
+	meta := p.Meta().(*apiClient)
+	ctx := context.Background()
+
+	networks := p.ResourcesMap["cloudconnexa_network"]
+	networkConfig := func(domain string) map[string]interface{} {
+		return map[string]interface{}{
+			"name":          "network",
+			"connector":     []interface{}{map[string]interface{}{"name": "west", "vpn_region_id": "us-west-1"}},
+			"default_route": []interface{}{map[string]interface{}{"type": "DOMAIN", "subnet": domain}},
+		}
+	}
+	network := testApply(t, networks, nil, networkConfig("example.com"), meta)
+	assert.Equal(t, "example.com", networks.Data(network).Get("default_route.0.subnet"))
+	network = testApply(t, networks, network, networkConfig("*.example.com"), meta)
+	network, diags := networks.RefreshWithoutUpgrade(ctx, network, meta)
+	require.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, "*.example.com", networks.Data(network).Get("default_route.0.subnet"), "the default route must be updated in place")
+	diff, err := networks.Diff(ctx, network, terraform.NewResourceConfigRaw(networkConfig("*.example.com")), meta)
+	require.NoError(t, err)
+	assert.Nil(t, diff)
+
+	routes := p.ResourcesMap["cloudconnexa_route"]
+	routeConfig := func(description string) map[string]interface{} {
+		return map[string]interface{}{"type": "DOMAIN", "subnet": "app.internal.example", "network_item_id": network.ID, "description": description}
+	}
+	route := testApply(t, routes, nil, routeConfig("first"), meta)
+	route = testApply(t, routes, route, routeConfig("second"), meta)
+	route, diags = routes.RefreshWithoutUpgrade(ctx, route, meta)
+	require.False(t, diags.HasError(), "%v", diags)
+	d := routes.Data(route)
+	assert.Equal(t, "DOMAIN", d.Get("type"))
+	assert.Equal(t, "app.internal.example", d.Get("subnet"))
+	assert.Equal(t, "second", d.Get("description"))
+
+	dataSource := p.DataSourcesMap["cloudconnexa_network_routes"]
+	d = dataSource.TestResourceData()
+	require.NoError(t, d.Set("network_item_id", network.ID))
+	diags = dataSource.ReadContext(ctx, d, meta)
+	require.False(t, diags.HasError(), "%v", diags)
+	assert.ElementsMatch(t, []interface{}{
+		map[string]interface{}{"id": networks.Data(network).Get("default_route.0.id"), "type": "DOMAIN", "subnet": "", "domain": "*.example.com", "description": "Managed by Terraform."},
+		map[string]interface{}{"id": route.ID, "type": "DOMAIN", "subnet": "", "domain": "app.internal.example", "description": "second"},
+	}, d.Get("routes"))
+
+	_, err = routes.Diff(ctx, nil, terraform.NewResourceConfigRaw(map[string]interface{}{
+		"type": "DOMAIN", "subnet": "10.0.0.0/8", "network_item_id": network.ID,
+	}), meta)
+	assert.ErrorContains(t, err, `subnet: "10.0.0.0/8" is not a valid domain`)
+}
+
+func TestResourceRoute_import(t *testing.T) {
+	server := fakeapi.NewServer()
+	defer server.Close()
+	p := newTestProvider(t, server)
+	meta := p.Meta().(*apiClient)
+	network := testApply(t, p.ResourcesMap["cloudconnexa_network"], nil, map[string]interface{}{
+		"name":          "network",
+		"connector":     []interface{}{map[string]interface{}{"name": "west", "vpn_region_id": "us-west-1"}},
+		"default_route": []interface{}{map[string]interface{}{"subnet": "10.1.0.0/16"}},
+	}, meta)
+
+	r := p.ResourcesMap["cloudconnexa_route"]
+	route := testApply(t, r, nil, map[string]interface{}{
+		"type":            "IP_V4",
+		"subnet":          "10.2.0.0/16",
+		"network_item_id": network.ID,
+		"description":     "imported",
+	}, meta)
+	testImportStateVerify(t, r, route.ID, route, meta)
+	testImportStateVerify(t, r, network.ID+"/"+route.ID, route, meta)
+
+	for _, id := range []string{network.ID + "/unknown", "unknown/" + route.ID, "/" + route.ID, network.ID + "/" + route.ID + "/extra"} {
+		_, err := r.Importer.StateContext(context.Background(), r.Data(&terraform.InstanceState{ID: id}), meta)
+		assert.Error(t, err, id)
+	}
 }
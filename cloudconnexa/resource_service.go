@@ -2,6 +2,8 @@ package cloudconnexa
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
 	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
@@ -20,6 +22,16 @@ func resourceIPService() *schema.Resource {
 		ReadContext:   resourceServiceRead,
 		DeleteContext: resourceServiceDelete,
 		UpdateContext: resourceServiceUpdate,
+		CustomizeDiff: customizeDiffIPService,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceServiceImport,
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(defaultTimeout),
+			Read:   schema.DefaultTimeout(defaultTimeout),
+			Update: schema.DefaultTimeout(defaultTimeout),
+			Delete: schema.DefaultTimeout(defaultTimeout),
+		},
 		Schema: map[string]*schema.Schema{
 			"id": {
 				Type:     schema.TypeString,
@@ -45,7 +57,9 @@ func resourceIPService() *schema.Resource {
 				Required: true,
 				MinItems: 1,
 				Elem: &schema.Schema{
-					Type: schema.TypeString,
+					Type:             schema.TypeString,
+					ValidateFunc:     validateRouteTarget,
+					DiffSuppressFunc: suppressEquivalentCIDRs,
 				},
 			},
 			"config": {
@@ -68,11 +82,11 @@ func resourceIPService() *schema.Resource {
 }
 
 func resourceServiceUpdate(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
-	c := i.(*cloudconnexa.Client)
+	c := i.(*apiClient).withContext(ctx)
 
 	s, err := c.IPServices.Update(data.Id(), resourceDataToService(data))
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromErr(ctx, err)
 	}
 	setResourceData(data, s)
 	return nil
@@ -127,15 +141,14 @@ func resourceServiceConfig() *schema.Resource {
 }
 
 func resourceServiceRead(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
-	c := i.(*cloudconnexa.Client)
+	c := i.(*apiClient).withContext(ctx)
 	var diags diag.Diagnostics
 	service, err := c.IPServices.Get(data.Id())
-	if err != nil {
-		return append(diags, diag.FromErr(err)...)
+	if isNotFound(err) || (err == nil && service == nil) {
+		return append(diags, removeFromState(data, "IP service")...)
 	}
-	if service == nil {
-		data.SetId("")
-		return diags
+	if err != nil {
+		return append(diags, diagFromErr(ctx, err)...)
 	}
 	setResourceData(data, service)
 	return diags
@@ -152,12 +165,38 @@ func setResourceData(data *schema.ResourceData, service *cloudconnexa.IPServiceR
 	_ = data.Set("network_item_id", service.NetworkItemId)
 }
 
+// resourceServiceImport accepts `<network_item_type>/<network_item_id>/<service_id>` as
+// well as the service ID alone, since the API returns the network item of a service.
+func resourceServiceImport(ctx context.Context, data *schema.ResourceData, i interface{}) ([]*schema.ResourceData, error) {
+	parts := strings.Split(data.Id(), "/")
+	if len(parts) == 1 {
+		return []*schema.ResourceData{data}, nil
+	}
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return nil, fmt.Errorf("unexpected import ID %q, expected <network_item_type>/<network_item_id>/<service_id> or <service_id>", data.Id())
+	}
+	networkItemType, networkItemId, serviceId := parts[0], parts[1], parts[2]
+	if networkItemType != "NETWORK" && networkItemType != "HOST" {
+		return nil, fmt.Errorf("unexpected network item type %q in import ID %q, expected NETWORK or HOST", networkItemType, data.Id())
+	}
+	c := i.(*apiClient).withContext(ctx)
+	service, err := c.IPServices.Get(serviceId)
+	if err != nil {
+		return nil, err
+	}
+	if service.NetworkItemType != networkItemType || service.NetworkItemId != networkItemId {
+		return nil, fmt.Errorf("IP service %s belongs to %s %s, not to %s %s", serviceId, service.NetworkItemType, service.NetworkItemId, networkItemType, networkItemId)
+	}
+	data.SetId(serviceId)
+	return []*schema.ResourceData{data}, nil
+}
+
 func resourceServiceDelete(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
-	c := i.(*cloudconnexa.Client)
+	c := i.(*apiClient).withContext(ctx)
 	var diags diag.Diagnostics
 	err := c.IPServices.Delete(data.Id())
 	if err != nil {
-		return append(diags, diag.FromErr(err)...)
+		return append(diags, diagFromErr(ctx, err)...)
 	}
 	return diags
 }
@@ -209,12 +248,12 @@ func flattenRoutes(routes []*cloudconnexa.Route) []string {
 }
 
 func resourceIPServiceCreate(ctx context.Context, data *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*cloudconnexa.Client)
+	client := m.(*apiClient).withContext(ctx)
 
 	service := resourceDataToService(data)
 	createdService, err := client.IPServices.Create(service)
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromErr(ctx, err)
 	}
 	setResourceData(data, createdService)
 	return nil
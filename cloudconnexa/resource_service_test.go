@@ -1,24 +1,27 @@
 package cloudconnexa
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 	"github.com/openvpn/cloudconnexa-go-client/v2/cloudconnexa"
 	"testing"
 
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/OpenVPN/terraform-provider-openvpn-cloud/internal/fakeapi"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestAccCloudConnexaService_basic(t *testing.T) {
-	rn := "cloudconnexa_service.test"
-	networkName := acctest.RandStringFromCharSet(10, alphabet)
+	random := testAccUseCassette(t)
+	rn := "cloudconnexa_ip_service.test"
+	networkName := random.RandStringFromCharSet(10, alphabet)
 	service := cloudconnexa.IPService{
-		Name: acctest.RandStringFromCharSet(10, alphabet),
+		Name: random.RandStringFromCharSet(10, alphabet),
 	}
 	serviceChanged := service
-	serviceChanged.Name = fmt.Sprintf("changed-%s", acctest.RandStringFromCharSet(10, alphabet))
+	serviceChanged.Name = fmt.Sprintf("changed-%s", random.RandStringFromCharSet(10, alphabet))
 
 	check := func(service cloudconnexa.IPService) resource.TestCheckFunc {
 		return resource.ComposeTestCheckFunc(
@@ -40,6 +43,17 @@ func TestAccCloudConnexaService_basic(t *testing.T) {
 				Config: testAccCloudConnexaServiceConfig(serviceChanged, networkName),
 				Check:  check(serviceChanged),
 			},
+			{
+				ResourceName:      rn,
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				ResourceName:      rn,
+				ImportState:       true,
+				ImportStateIdFunc: testAccCloudConnexaServiceCompositeImportStateIdFunc(rn),
+				ImportStateVerify: true,
+			},
 		},
 	})
 }
@@ -55,7 +69,7 @@ func testAccCheckCloudConnexaServiceExists(rn, networkId string) resource.TestCh
 			return errors.New("no ID is set")
 		}
 
-		c := testAccProvider.Meta().(*cloudconnexa.Client)
+		c := testAccProvider.Meta().(*apiClient).client
 		_, err := c.IPServices.Get(rs.Primary.ID)
 		if err != nil {
 			return err
@@ -64,10 +78,21 @@ func testAccCheckCloudConnexaServiceExists(rn, networkId string) resource.TestCh
 	}
 }
 
+func testAccCloudConnexaServiceCompositeImportStateIdFunc(rn string) resource.ImportStateIdFunc {
+	return func(s *terraform.State) (string, error) {
+		rs, ok := s.RootModule().Resources[rn]
+		if !ok {
+			return "", fmt.Errorf("not found: %s", rn)
+		}
+		a := rs.Primary.Attributes
+		return a["network_item_type"] + "/" + a["network_item_id"] + "/" + rs.Primary.ID, nil
+	}
+}
+
 func testAccCheckCloudConnexaServiceDestroy(state *terraform.State) error {
-	c := testAccProvider.Meta().(*cloudconnexa.Client)
+	c := testAccProvider.Meta().(*apiClient).client
 	for _, rs := range state.RootModule().Resources {
-		if rs.Type != "cloudconnexa_service" {
+		if rs.Type != "cloudconnexa_ip_service" {
 			continue
 		}
 		id := rs.Primary.Attributes["id"]
@@ -79,11 +104,7 @@ func testAccCheckCloudConnexaServiceDestroy(state *terraform.State) error {
 	return nil
 }
 func testAccCloudConnexaServiceConfig(service cloudconnexa.IPService, networkName string) string {
-	return fmt.Sprintf(`
-provider "cloudconnexa" {
-	base_url = "https://%s.api.openvpn.com"
-}
-
+	return fmt.Sprintf(`%s
 resource "cloudconnexa_network" "test" {
 	name = "%s"
 	description = "test"
@@ -93,7 +114,7 @@ resource "cloudconnexa_network" "test" {
 	  vpn_region_id = "fi-hel"
 	}
 	default_route {
-	  value = "10.1.2.0/24"
+	  subnet = "10.1.2.0/24"
 	  type  = "IP_V4"
 	}
 }
@@ -109,5 +130,40 @@ resource "cloudconnexa_ip_service" "test" {
 		service_types = ["ANY"]
 	}
 }
-`, testCloudID, networkName, fmt.Sprintf("connector_%s", networkName), service.Name)
+`, testAccProviderConfig(), networkName, fmt.Sprintf("connector_%s", networkName), service.Name)
+}
+
+func TestResourceIPService_import(t *testing.T) {
+	server := fakeapi.NewServer()
+	defer server.Close()
+	p := newTestProvider(t, server)
+	meta := p.Meta().(*apiClient)
+	network := testApply(t, p.ResourcesMap["cloudconnexa_network"], nil, map[string]interface{}{
+		"name":          "network",
+		"connector":     []interface{}{map[string]interface{}{"name": "west", "vpn_region_id": "us-west-1"}},
+		"default_route": []interface{}{map[string]interface{}{"subnet": "10.1.0.0/16"}},
+	}, meta)
+
+	r := p.ResourcesMap["cloudconnexa_ip_service"]
+	service := testApply(t, r, nil, map[string]interface{}{
+		"name":              "service",
+		"type":              "SERVICE_DESTINATION",
+		"routes":            []interface{}{"10.1.0.10/32"},
+		"config":            []interface{}{map[string]interface{}{"service_types": []interface{}{"HTTPS"}}},
+		"network_item_type": "NETWORK",
+		"network_item_id":   network.ID,
+	}, meta)
+	testImportStateVerify(t, r, service.ID, service, meta)
+	testImportStateVerify(t, r, "NETWORK/"+network.ID+"/"+service.ID, service, meta)
+
+	for _, id := range []string{
+		"HOST/" + network.ID + "/" + service.ID,
+		"NETWORK/other/" + service.ID,
+		"NETWORK/" + network.ID + "/unknown",
+		"SUBNET/" + network.ID + "/" + service.ID,
+		network.ID + "/" + service.ID,
+	} {
+		_, err := r.Importer.StateContext(context.Background(), r.Data(&terraform.InstanceState{ID: id}), meta)
+		assert.Error(t, err, id)
+	}
 }
@@ -18,6 +18,12 @@ func resourceUser() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(defaultTimeout),
+			Read:   schema.DefaultTimeout(defaultTimeout),
+			Update: schema.DefaultTimeout(defaultTimeout),
+			Delete: schema.DefaultTimeout(defaultTimeout),
+		},
 		Schema: map[string]*schema.Schema{
 			"username": {
 				Type:         schema.TypeString,
@@ -94,7 +100,7 @@ func resourceUser() *schema.Resource {
 }
 
 func resourceUserCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*cloudconnexa.Client)
+	c := m.(*apiClient).withContext(ctx)
 	var diags diag.Diagnostics
 	username := d.Get("username").(string)
 	email := d.Get("email").(string)
@@ -128,7 +134,7 @@ func resourceUserCreate(ctx context.Context, d *schema.ResourceData, m interface
 	}
 	user, err := c.Users.Create(u)
 	if err != nil {
-		return append(diags, diag.FromErr(err)...)
+		return append(diags, diagFromErr(ctx, err)...)
 	}
 	d.SetId(user.Id)
 	return append(diags, diag.Diagnostic{
@@ -139,48 +145,45 @@ func resourceUserCreate(ctx context.Context, d *schema.ResourceData, m interface
 }
 
 func resourceUserRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*cloudconnexa.Client)
+	c := m.(*apiClient).withContext(ctx)
 	var diags diag.Diagnostics
 	userId := d.Id()
-	u, err := c.Users.Get(userId)
+	u, err := getUser(c, userId)
+	if err != nil {
+		return append(diags, diagFromErr(ctx, err)...)
+	}
+	if u == nil {
+		return append(diags, removeFromState(d, "user")...)
+	}
 
 	// If group_id is not set, Cloud Connexa sets it to the default group.
 	var groupId string
-	if d.Get("group_id") == "" {
-		// The group has not been explicitly set.
-		// Set it to an empty string to keep the default group.
-		groupId = ""
-	} else {
+	if d.Get("group_id") != "" {
 		groupId = u.GroupId
 	}
-
-	if err != nil {
-		return append(diags, diag.FromErr(err)...)
-	}
-	if u == nil {
-		d.SetId("")
-	} else {
-		d.Set("username", u.Username)
-		d.Set("email", u.Email)
-		d.Set("first_name", u.FirstName)
-		d.Set("last_name", u.LastName)
-		d.Set("group_id", groupId)
-		d.Set("devices", u.Devices)
-		d.Set("role", u.Role)
-	}
+	d.Set("username", u.Username)
+	d.Set("email", u.Email)
+	d.Set("first_name", u.FirstName)
+	d.Set("last_name", u.LastName)
+	d.Set("group_id", groupId)
+	d.Set("devices", u.Devices)
+	d.Set("role", u.Role)
 	return diags
 }
 
 func resourceUserUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*cloudconnexa.Client)
+	c := m.(*apiClient).withContext(ctx)
 	var diags diag.Diagnostics
 	if !d.HasChanges("first_name", "last_name", "group_id", "email") {
 		return diags
 	}
 
-	u, err := c.Users.Get(d.Id())
+	u, err := getUser(c, d.Id())
 	if err != nil {
-		return append(diags, diag.FromErr(err)...)
+		return append(diags, diagFromErr(ctx, err)...)
+	}
+	if u == nil {
+		return append(diags, diag.Errorf("User with ID %s was not found", d.Id())...)
 	}
 
 	_, email := d.GetChange("email")
@@ -196,7 +199,7 @@ func resourceUserUpdate(ctx context.Context, d *schema.ResourceData, m interface
 	if oldGroupId.(string) == "" && groupId == "" {
 		g, err := c.UserGroups.GetByName("Default")
 		if err != nil {
-			return append(diags, diag.FromErr(err)...)
+			return append(diags, diagFromErr(ctx, err)...)
 		}
 		groupId = g.ID
 	}
@@ -211,16 +214,35 @@ func resourceUserUpdate(ctx context.Context, d *schema.ResourceData, m interface
 		Status:    status,
 	})
 
-	return append(diags, diag.FromErr(err)...)
+	return append(diags, diagFromErr(ctx, err)...)
 }
 
 func resourceUserDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*cloudconnexa.Client)
+	c := m.(*apiClient).withContext(ctx)
 	var diags diag.Diagnostics
 	userId := d.Id()
 	err := c.Users.Delete(userId)
 	if err != nil {
-		return append(diags, diag.FromErr(err)...)
+		return append(diags, diagFromErr(ctx, err)...)
 	}
 	return diags
 }
+
+// getUser returns the user with the given ID, or nil if there is none. Users.Get of the
+// client library starts at the second page and so never finds the first users.
+func getUser(c *cloudconnexa.Client, userId string) (*cloudconnexa.User, error) {
+	for page := 0; ; page++ {
+		response, err := c.Users.GetByPage(page, 10)
+		if err != nil {
+			return nil, err
+		}
+		for _, u := range response.Content {
+			if u.Id == userId {
+				return &u, nil
+			}
+		}
+		if page+1 >= response.TotalPages {
+			return nil, nil
+		}
+	}
+}
@@ -18,6 +18,12 @@ func resourceUserGroup() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(defaultTimeout),
+			Read:   schema.DefaultTimeout(defaultTimeout),
+			Update: schema.DefaultTimeout(defaultTimeout),
+			Delete: schema.DefaultTimeout(defaultTimeout),
+		},
 		Schema: map[string]*schema.Schema{
 			"id": {
 				Type:        schema.TypeString,
@@ -55,7 +61,9 @@ func resourceUserGroup() *schema.Resource {
 				Default:     nil,
 				Description: "A list of subnets that are accessible to the user group.",
 				Elem: &schema.Schema{
-					Type: schema.TypeString,
+					Type:             schema.TypeString,
+					ValidateFunc:     validation.IsCIDR,
+					DiffSuppressFunc: suppressEquivalentCIDRs,
 				},
 			},
 			"vpn_region_ids": {
@@ -67,18 +75,19 @@ func resourceUserGroup() *schema.Resource {
 					Type: schema.TypeString,
 				},
 			},
+			"deletion_protection": deletionProtectionSchema(),
 		},
 	}
 }
 
 func resourceUserGroupUpdate(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
-	c := i.(*cloudconnexa.Client)
+	c := i.(*apiClient).withContext(ctx)
 	var diags diag.Diagnostics
 	ug := resourceDataToUserGroup(data)
 
 	userGroup, err := c.UserGroups.Update(data.Id(), ug)
 	if err != nil {
-		return append(diags, diag.FromErr(err)...)
+		return append(diags, diagFromErr(ctx, err)...)
 	}
 
 	if userGroup == nil {
@@ -128,40 +137,38 @@ func updateUserGroupData(data *schema.ResourceData, userGroup *cloudconnexa.User
 }
 
 func resourceUserGroupDelete(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
-	c := i.(*cloudconnexa.Client)
+	c := i.(*apiClient).withContext(ctx)
 	var diags diag.Diagnostics
 	err := c.UserGroups.Delete(data.Id())
 	if err != nil {
-		return append(diags, diag.FromErr(err)...)
+		return append(diags, diagFromErr(ctx, err)...)
 	}
 	data.SetId("")
 	return diags
 }
 
 func resourceUserGroupRead(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
-	c := i.(*cloudconnexa.Client)
+	c := i.(*apiClient).withContext(ctx)
 	var diags diag.Diagnostics
 	userGroup, err := c.UserGroups.Get(data.Id())
-	if err != nil {
-		return append(diags, diag.FromErr(err)...)
+	if isNotFound(err) || (err == nil && userGroup == nil) {
+		return append(diags, removeFromState(data, "user group")...)
 	}
-
-	if userGroup == nil {
-		data.SetId("")
-	} else {
-		updateUserGroupData(data, userGroup)
+	if err != nil {
+		return append(diags, diagFromErr(ctx, err)...)
 	}
+	updateUserGroupData(data, userGroup)
 	return diags
 }
 
 func resourceUserGroupCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*cloudconnexa.Client)
+	c := m.(*apiClient).withContext(ctx)
 	var diags diag.Diagnostics
 	ug := resourceDataToUserGroup(d)
 
 	userGroup, err := c.UserGroups.Create(ug)
 	if err != nil {
-		return append(diags, diag.FromErr(err)...)
+		return append(diags, diagFromErr(ctx, err)...)
 	}
 	updateUserGroupData(d, userGroup)
 	return diags
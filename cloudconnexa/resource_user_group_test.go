@@ -7,21 +7,21 @@ import (
 	"github.com/openvpn/cloudconnexa-go-client/v2/cloudconnexa"
 	"testing"
 
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
 func TestAccCloudConnexaUserGroup_basic(t *testing.T) {
+	random := testAccUseCassette(t)
 	rn := "cloudconnexa_user_group.test"
 	userGroup := cloudconnexa.UserGroup{
-		Name: acctest.RandStringFromCharSet(10, alphabet),
+		Name: random.RandStringFromCharSet(10, alphabet),
 		VpnRegionIds: []string{
 			"us-east-1",
 		},
 	}
 	userGroupChanged := userGroup
-	userGroupChanged.Name = fmt.Sprintf("changed-%s", acctest.RandStringFromCharSet(10, alphabet))
+	userGroupChanged.Name = fmt.Sprintf("changed-%s", random.RandStringFromCharSet(10, alphabet))
 
 	check := func(userGroup cloudconnexa.UserGroup) resource.TestCheckFunc {
 		return resource.ComposeTestCheckFunc(
@@ -55,7 +55,7 @@ func TestAccCloudConnexaUserGroup_basic(t *testing.T) {
 }
 
 func testAccCheckCloudConnexaUserGroupDestroy(s *terraform.State) error {
-	c := testAccProvider.Meta().(*cloudconnexa.Client)
+	c := testAccProvider.Meta().(*apiClient).client
 	for _, rs := range s.RootModule().Resources {
 		if rs.Type != "cloudconnexa_user_group" {
 			continue
@@ -82,7 +82,7 @@ func testAccCheckCloudConnexaUserGroupExists(rn string) resource.TestCheckFunc {
 			return errors.New("no ID is set")
 		}
 
-		c := testAccProvider.Meta().(*cloudconnexa.Client)
+		c := testAccProvider.Meta().(*apiClient).client
 		_, err := c.UserGroups.Get(rs.Primary.ID)
 		if err != nil {
 			return err
@@ -94,14 +94,10 @@ func testAccCheckCloudConnexaUserGroupExists(rn string) resource.TestCheckFunc {
 func testAccCloudConnexaUserGroupConfig(userGroup cloudconnexa.UserGroup) string {
 	idsStr, _ := json.Marshal(userGroup.VpnRegionIds)
 
-	return fmt.Sprintf(`
-provider "cloudconnexa" {
-	base_url = "https://%s.api.openvpn.com"
-}
-resource "cloudconnexa_user_group" "test" {
+	return fmt.Sprintf(`%sresource "cloudconnexa_user_group" "test" {
 	name = "%s"
 	vpn_region_ids = %s
 
 }
-`, testCloudID, userGroup.Name, idsStr)
+`, testAccProviderConfig(), userGroup.Name, idsStr)
 }
@@ -6,21 +6,24 @@ import (
 	"github.com/openvpn/cloudconnexa-go-client/v2/cloudconnexa"
 	"testing"
 
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/OpenVPN/terraform-provider-openvpn-cloud/internal/fakeapi"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestAccCloudConnexaUser_basic(t *testing.T) {
+	random := testAccUseCassette(t)
 	rn := "cloudconnexa_user.test"
 	user := cloudconnexa.User{
-		Username:  acctest.RandStringFromCharSet(10, alphabet),
-		FirstName: acctest.RandStringFromCharSet(10, alphabet),
-		LastName:  acctest.RandStringFromCharSet(10, alphabet),
-		Email:     fmt.Sprintf("terraform-tests+%s@devopenvpn.in", acctest.RandString(10)),
+		Username:  random.RandStringFromCharSet(10, alphabet),
+		FirstName: random.RandStringFromCharSet(10, alphabet),
+		LastName:  random.RandStringFromCharSet(10, alphabet),
+		Email:     fmt.Sprintf("terraform-tests+%s@devopenvpn.in", random.RandString(10)),
 	}
 	userChanged := user
-	userChanged.Email = fmt.Sprintf("terraform-tests+changed%s@devopenvpn.in", acctest.RandString(10))
+	userChanged.Email = fmt.Sprintf("terraform-tests+changed%s@devopenvpn.in", random.RandString(10))
 	var userID string
 
 	check := func(user cloudconnexa.User) resource.TestCheckFunc {
@@ -57,17 +60,17 @@ func TestAccCloudConnexaUser_basic(t *testing.T) {
 }
 
 func testAccCheckCloudConnexaUserDestroy(s *terraform.State) error {
-	client := testAccProvider.Meta().(*cloudconnexa.Client)
+	client := testAccProvider.Meta().(*apiClient).client
 	for _, rs := range s.RootModule().Resources {
 		if rs.Type != "cloudconnexa_user" {
 			continue
 		}
-		username := rs.Primary.Attributes["username"]
-		u, err := client.Users.Get(username)
-		if err == nil {
-			if u != nil {
-				return errors.New("user still exists")
-			}
+		u, err := getUser(client, rs.Primary.ID)
+		if err != nil {
+			return err
+		}
+		if u != nil {
+			return errors.New("user still exists")
 		}
 	}
 	return nil
@@ -84,11 +87,14 @@ func testAccCheckCloudConnexaUserExists(n string, teamID *string) resource.TestC
 			return errors.New("no ID is set")
 		}
 
-		client := testAccProvider.Meta().(*cloudconnexa.Client)
-		_, err := client.Users.Get(rs.Primary.ID)
+		client := testAccProvider.Meta().(*apiClient).client
+		u, err := getUser(client, rs.Primary.ID)
 		if err != nil {
 			return err
 		}
+		if u == nil {
+			return fmt.Errorf("user %s does not exist", rs.Primary.ID)
+		}
 		return nil
 	}
 }
@@ -104,15 +110,44 @@ func testAccCloudConnexaUserImportStateIdFunc(n string) resource.ImportStateIdFu
 }
 
 func testAccCloudConnexaUserConfig(user cloudconnexa.User) string {
-	return fmt.Sprintf(`
-provider "cloudconnexa" {
-	base_url = "https://%s.api.openvpn.com"
-}
-resource "cloudconnexa_user" "test" {
+	return fmt.Sprintf(`%sresource "cloudconnexa_user" "test" {
 	username   = "%s"
 	email      = "%s"
 	first_name = "%s"
 	last_name  = "%s"
 }
-`, testCloudID, user.Username, user.Email, user.FirstName, user.LastName)
+`, testAccProviderConfig(), user.Username, user.Email, user.FirstName, user.LastName)
+}
+
+func TestGetUser(t *testing.T) {
+	server := fakeapi.NewServer()
+	defer server.Close()
+	c := newTestProvider(t, server).Meta().(*apiClient).client
+
+	var ids []string
+	for i := 0; i < 12; i++ {
+		u, err := c.Users.Create(cloudconnexa.User{
+			Username:  fmt.Sprintf("user%d", i),
+			Email:     fmt.Sprintf("user%d@example.com", i),
+			FirstName: "First",
+			LastName:  "Last",
+		})
+		require.NoError(t, err)
+		ids = append(ids, u.Id)
+	}
+
+	// Users.Get of the client library starts listing at the second page, unlike every
+	// other listing of the library, so it misses the users of the first page.
+	_, err := c.Users.Get(ids[0])
+	require.Error(t, err)
+
+	for _, id := range []string{ids[0], ids[11]} {
+		u, err := getUser(c, id)
+		require.NoError(t, err)
+		require.NotNil(t, u)
+		assert.Equal(t, id, u.Id)
+	}
+	u, err := getUser(c, "missing")
+	require.NoError(t, err)
+	assert.Nil(t, u)
 }
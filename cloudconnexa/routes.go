@@ -0,0 +1,159 @@
+package cloudconnexa
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"regexp"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/openvpn/cloudconnexa-go-client/v2/cloudconnexa"
+)
+
+// networkRoute is a route as returned by the API. cloudconnexa.Route has no field for
+// the domain of DOMAIN routes and the client sends the wrong body to update routes,
+// so the provider makes the route requests itself.
+type networkRoute struct {
+	cloudconnexa.Route
+	Domain string `json:"domain,omitempty"`
+}
+
+// value returns the subnet of IP routes and the domain of DOMAIN routes.
+func (r *networkRoute) value() string {
+	if r.Type == "DOMAIN" && r.Domain != "" {
+		return r.Domain
+	}
+	return r.Subnet
+}
+
+type networkRoutePage struct {
+	Content    []networkRoute `json:"content"`
+	TotalPages int            `json:"totalPages"`
+}
+
+// routeRequest is the body of the requests that create and update routes. The API
+// infers the type of the route from its value.
+type routeRequest struct {
+	Description string `json:"description"`
+	Value       string `json:"value"`
+}
+
+// domainRoutePattern matches fully qualified domain names, optionally preceded by a
+// `*.` wildcard label.
+var domainRoutePattern = regexp.MustCompile(`^(\*\.)?([a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?\.)+[a-zA-Z]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?$`)
+
+// validateRouteValue checks that value can be the target of a route of the given type.
+func validateRouteValue(routeType, value string) error {
+	switch routeType {
+	case "DOMAIN":
+		if len(value) > 253 || !domainRoutePattern.MatchString(value) {
+			return fmt.Errorf("%q is not a valid domain: expected a fully qualified domain name such as `example.com` or a wildcard such as `*.example.com`", value)
+		}
+	case "IP_V4":
+		if ip, _, err := net.ParseCIDR(value); err != nil || ip.To4() == nil {
+			return fmt.Errorf("%q is not a valid IPv4 CIDR such as `10.0.0.0/16`", value)
+		}
+	case "IP_V6":
+		if ip, _, err := net.ParseCIDR(value); err != nil || ip.To4() != nil {
+			return fmt.Errorf("%q is not a valid IPv6 CIDR such as `fd00::/64`", value)
+		}
+	}
+	return nil
+}
+
+// validateRouteTarget is the ValidateFunc of routes given without a type, such as the
+// routes of IP services, whose type is inferred from their value.
+func validateRouteTarget(i interface{}, k string) ([]string, []error) {
+	value, ok := i.(string)
+	if !ok {
+		return nil, []error{fmt.Errorf("expected type of %s to be string", k)}
+	}
+	if err := validateRouteValue(routeTypeOf(value), value); err != nil {
+		return nil, []error{fmt.Errorf("%s: %w", k, err)}
+	}
+	return nil, nil
+}
+
+// customizeDiffRouteValue checks the value of a route against its type, which the
+// validation of a single attribute cannot see.
+func customizeDiffRouteValue(typeKey, valueKey string) schema.CustomizeDiffFunc {
+	return func(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+		if !d.NewValueKnown(typeKey) || !d.NewValueKnown(valueKey) {
+			return nil
+		}
+		if err := validateRouteValue(d.Get(typeKey).(string), d.Get(valueKey).(string)); err != nil {
+			return fmt.Errorf("%s: %w", valueKey, err)
+		}
+		return nil
+	}
+}
+
+func listRoutes(c *cloudconnexa.Client, networkId string) ([]networkRoute, error) {
+	var routes []networkRoute
+	for page := 0; ; page++ {
+		var response networkRoutePage
+		err := doJSONRequest(c, http.MethodGet, fmt.Sprintf("%s/api/beta/networks/%s/routes/page?page=%d&size=%d", c.BaseURL, networkId, page, 10), nil, &response)
+		if err != nil {
+			return nil, err
+		}
+		routes = append(routes, response.Content...)
+		if page+1 >= response.TotalPages {
+			return routes, nil
+		}
+	}
+}
+
+// getNetworkRoute returns the route of the network with the given ID, or nil if there
+// is none.
+func getNetworkRoute(c *cloudconnexa.Client, networkId, routeId string) (*networkRoute, error) {
+	routes, err := listRoutes(c, networkId)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range routes {
+		if r.Id == routeId {
+			r.NetworkItemId = networkId
+			return &r, nil
+		}
+	}
+	return nil, nil
+}
+
+// getRoute looks for the route with the given ID in every network, and returns nil if
+// there is none.
+func getRoute(c *cloudconnexa.Client, routeId string) (*networkRoute, error) {
+	networks, err := c.Networks.List()
+	if err != nil {
+		return nil, err
+	}
+	for _, n := range networks {
+		r, err := getNetworkRoute(c, n.Id, routeId)
+		if err != nil {
+			return nil, err
+		}
+		if r != nil {
+			return r, nil
+		}
+	}
+	return nil, nil
+}
+
+func createRoute(c *cloudconnexa.Client, networkId, value, description string) (*networkRoute, error) {
+	var r networkRoute
+	err := doJSONRequest(c, http.MethodPost, fmt.Sprintf("%s/api/beta/networks/%s/routes", c.BaseURL, networkId), routeRequest{
+		Description: description,
+		Value:       value,
+	}, &r)
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+func updateRoute(c *cloudconnexa.Client, networkId, routeId, value, description string) error {
+	return doJSONRequest(c, http.MethodPut, fmt.Sprintf("%s/api/beta/networks/%s/routes/%s", c.BaseURL, networkId, routeId), routeRequest{
+		Description: description,
+		Value:       value,
+	}, nil)
+}
@@ -0,0 +1,221 @@
+package cloudconnexa
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// contextTransport binds requests to the context of the Terraform operation that
+// issued them. The client library builds its requests without a context, so without
+// this a resource timeout would not interrupt an API call that is already in flight.
+type contextTransport struct {
+	ctx  context.Context
+	next http.RoundTripper
+}
+
+func (t *contextTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// The request context may already be canceled by the caller, so a cancellation of
+	// either context aborts the request.
+	ctx, cancel := context.WithCancel(t.ctx)
+	stop := context.AfterFunc(req.Context(), cancel)
+	release := func() {
+		stop()
+		cancel()
+	}
+	resp, err := t.next.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		release()
+		return nil, err
+	}
+	resp.Body = &releaseOnClose{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+type releaseOnClose struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *releaseOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.release()
+	return err
+}
+
+// rateLimitTransport makes every request, including retries, wait for a token from a
+// single bucket so that all resources of a provider instance share the API quota. The
+// wait is only bounded by the timeout of the Terraform operation, as requests queued
+// behind many others may legitimately wait longer than a single request takes.
+type rateLimitTransport struct {
+	next    http.RoundTripper
+	limiter *rate.Limiter
+}
+
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.limiter.Wait(req.Context()); err != nil {
+		if req.Context().Err() == nil {
+			// The limiter fails right away when the wait would outlast the deadline.
+			return nil, fmt.Errorf("the request could not be sent within the timeout of the operation because of the requests_per_second limit: %w", err)
+		}
+		return nil, err
+	}
+	return t.next.RoundTrip(req)
+}
+
+// timeoutTransport bounds each attempt of a request, including reading its response. It
+// sits below the retry and rate limiting transports, so that neither the waits between
+// retries nor the wait for the rate limiter count against the timeout.
+type timeoutTransport struct {
+	next    http.RoundTripper
+	timeout time.Duration
+}
+
+func (t *timeoutTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, cancel := context.WithTimeout(req.Context(), t.timeout)
+	resp, err := t.next.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		if ctx.Err() != nil && req.Context().Err() == nil {
+			return nil, fmt.Errorf("no response from the Cloud Connexa API within %v: %w", t.timeout, err)
+		}
+		return nil, err
+	}
+	resp.Body = &releaseOnClose{ReadCloser: resp.Body, release: cancel}
+	return resp, nil
+}
+
+// newRateLimiter returns the limiter shared by all requests of a provider instance.
+// A requestsPerSecond of zero disables client-side rate limiting.
+func newRateLimiter(requestsPerSecond float64, burst int) *rate.Limiter {
+	if requestsPerSecond <= 0 {
+		return rate.NewLimiter(rate.Inf, burst)
+	}
+	return rate.NewLimiter(rate.Limit(requestsPerSecond), burst)
+}
+
+// retryTransport retries requests that were throttled (429), and idempotent requests
+// that failed with a server error (5xx), waiting with jittered exponential backoff
+// between attempts. A Retry-After header sent by the API is honored, bounded by
+// maxWait.
+type retryTransport struct {
+	next       http.RoundTripper
+	maxRetries int
+	minWait    time.Duration
+	maxWait    time.Duration
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		attemptReq := req
+		if attempt > 0 {
+			var err error
+			attemptReq, err = rewindRequest(req)
+			if err != nil {
+				return nil, err
+			}
+		}
+		resp, err := t.next.RoundTrip(attemptReq)
+		if err != nil || !isRetryable(req, resp.StatusCode) || attempt >= t.maxRetries {
+			return resp, err
+		}
+		if req.Body != nil && req.GetBody == nil {
+			// The body has been consumed and cannot be sent again.
+			return resp, nil
+		}
+		wait := t.backoff(attempt, resp)
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff returns the time to wait before the next attempt: min*2^attempt with full
+// jitter over its upper half, raised to the Retry-After value when the API sent one.
+func (t *retryTransport) backoff(attempt int, resp *http.Response) time.Duration {
+	wait := t.minWait << uint(attempt)
+	if wait <= 0 || wait > t.maxWait {
+		wait = t.maxWait
+	}
+	if wait > 0 {
+		wait = wait/2 + time.Duration(rand.Int63n(int64(wait/2)+1))
+	}
+	if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok && retryAfter > wait {
+		wait = retryAfter
+	}
+	if wait > t.maxWait {
+		wait = t.maxWait
+	}
+	return wait
+}
+
+// isRetryable reports whether a request that got the given status may be sent again.
+// A throttled request was not processed, but a server error may come after the object
+// was created, so only the requests that do not create objects are retried then.
+func isRetryable(req *http.Request, statusCode int) bool {
+	if statusCode == http.StatusTooManyRequests {
+		return true
+	}
+	return statusCode >= 500 && isIdempotent(req)
+}
+
+// isIdempotent follows http.Transport: the idempotent methods, and the requests that
+// carry an Idempotency-Key header, even a nil one.
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	if _, ok := req.Header["Idempotency-Key"]; ok {
+		return true
+	}
+	_, ok := req.Header["X-Idempotency-Key"]
+	return ok
+}
+
+// parseRetryAfter understands both forms of the header: delay-seconds and HTTP-date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		wait := time.Until(date)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+	return 0, false
+}
+
+// rewindRequest returns a copy of req with a fresh body so it can be sent again.
+func rewindRequest(req *http.Request) (*http.Request, error) {
+	clone := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		clone.Body = body
+	}
+	return clone, nil
+}
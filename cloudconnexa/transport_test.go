@@ -0,0 +1,404 @@
+package cloudconnexa
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/openvpn/cloudconnexa-go-client/v2/cloudconnexa"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+)
+
+// newThrottlingServer returns a stand-in for the Cloud Connexa API that answers the
+// first failures requests to every endpoint but the token one with status.
+func newThrottlingServer(t *testing.T, failures int32, status int, retryAfter string) (*httptest.Server, *int32) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/beta/oauth/token" {
+			_ = json.NewEncoder(w).Encode(map[string]string{"access_token": "token"})
+			return
+		}
+		if atomic.AddInt32(&calls, 1) <= failures {
+			if retryAfter != "" {
+				w.Header().Set("Retry-After", retryAfter)
+			}
+			w.WriteHeader(status)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func newTestRetryTransport(maxRetries int) *retryTransport {
+	return &retryTransport{
+		next:       http.DefaultTransport,
+		maxRetries: maxRetries,
+		minWait:    time.Millisecond,
+		maxWait:    10 * time.Millisecond,
+	}
+}
+
+func TestRetryTransport_retriesThrottledRequests(t *testing.T) {
+	server, calls := newThrottlingServer(t, 2, http.StatusTooManyRequests, "0")
+	client := &http.Client{Transport: newTestRetryTransport(3)}
+
+	resp, err := client.Post(server.URL+"/api/beta/networks", "application/json", strings.NewReader(`{"name":"test"}`))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, `{"name":"test"}`, string(body), "the request body must be replayed on every attempt")
+	assert.EqualValues(t, 3, atomic.LoadInt32(calls))
+}
+
+func TestRetryTransport_retriesServerErrors(t *testing.T) {
+	server, calls := newThrottlingServer(t, 1, http.StatusBadGateway, "")
+	client := &http.Client{Transport: newTestRetryTransport(3)}
+
+	resp, err := client.Get(server.URL + "/api/beta/networks/page")
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.EqualValues(t, 2, atomic.LoadInt32(calls))
+}
+
+func TestRetryTransport_givesUpAfterMaxRetries(t *testing.T) {
+	server, calls := newThrottlingServer(t, 10, http.StatusServiceUnavailable, "")
+	client := &http.Client{Transport: newTestRetryTransport(2)}
+
+	resp, err := client.Get(server.URL + "/api/beta/networks/page")
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.EqualValues(t, 3, atomic.LoadInt32(calls))
+}
+
+func TestRetryTransport_doesNotRetryClientErrors(t *testing.T) {
+	server, calls := newThrottlingServer(t, 1, http.StatusBadRequest, "")
+	client := &http.Client{Transport: newTestRetryTransport(3)}
+
+	resp, err := client.Get(server.URL + "/api/beta/networks/page")
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.EqualValues(t, 1, atomic.LoadInt32(calls))
+}
+
+func TestRetryTransport_doesNotRetryServerErrorsOfCreations(t *testing.T) {
+	server, calls := newThrottlingServer(t, 1, http.StatusBadGateway, "")
+	client := &http.Client{Transport: newTestRetryTransport(3)}
+
+	resp, err := client.Post(server.URL+"/api/beta/networks", "application/json", strings.NewReader(`{"name":"test"}`))
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusBadGateway, resp.StatusCode, "the network may have been created")
+	assert.EqualValues(t, 1, atomic.LoadInt32(calls))
+}
+
+func TestIsRetryable(t *testing.T) {
+	for _, tc := range []struct {
+		method    string
+		header    http.Header
+		status    int
+		retryable bool
+	}{
+		{http.MethodPost, nil, http.StatusTooManyRequests, true},
+		{http.MethodPost, nil, http.StatusInternalServerError, false},
+		{http.MethodPost, http.Header{"Idempotency-Key": nil}, http.StatusInternalServerError, true},
+		{http.MethodGet, nil, http.StatusServiceUnavailable, true},
+		{http.MethodPut, nil, http.StatusBadGateway, true},
+		{http.MethodDelete, nil, http.StatusGatewayTimeout, true},
+		{http.MethodGet, nil, http.StatusNotFound, false},
+	} {
+		req := &http.Request{Method: tc.method, Header: tc.header}
+		assert.Equal(t, tc.retryable, isRetryable(req, tc.status), "%s %v %d", tc.method, tc.header, tc.status)
+	}
+}
+
+func TestRetryTransport_honorsRetryAfter(t *testing.T) {
+	server, _ := newThrottlingServer(t, 1, http.StatusTooManyRequests, "1")
+	transport := newTestRetryTransport(1)
+	transport.maxWait = 5 * time.Second
+	client := &http.Client{Transport: transport}
+
+	start := time.Now()
+	resp, err := client.Get(server.URL + "/api/beta/networks/page")
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.GreaterOrEqual(t, time.Since(start), time.Second)
+}
+
+func TestRetryTransport_backoff(t *testing.T) {
+	transport := &retryTransport{minWait: time.Second, maxWait: 10 * time.Second}
+	resp := &http.Response{Header: http.Header{}}
+
+	for attempt, upper := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second, 10 * time.Second} {
+		wait := transport.backoff(attempt, resp)
+		assert.GreaterOrEqual(t, wait, upper/2, "attempt %d", attempt)
+		assert.LessOrEqual(t, wait, upper, "attempt %d", attempt)
+	}
+
+	resp.Header.Set("Retry-After", "7")
+	assert.Equal(t, 7*time.Second, transport.backoff(0, resp))
+	resp.Header.Set("Retry-After", "60")
+	assert.Equal(t, 10*time.Second, transport.backoff(0, resp), "Retry-After must be capped at maxWait")
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	wait, ok := parseRetryAfter("3")
+	assert.True(t, ok)
+	assert.Equal(t, 3*time.Second, wait)
+
+	wait, ok = parseRetryAfter(time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat))
+	assert.True(t, ok)
+	assert.Equal(t, time.Duration(0), wait)
+
+	_, ok = parseRetryAfter("soon")
+	assert.False(t, ok)
+	_, ok = parseRetryAfter("")
+	assert.False(t, ok)
+}
+
+func TestNewClient_retriesThroughTransport(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/beta/oauth/token":
+			user, pass, ok := r.BasicAuth()
+			assert.True(t, ok)
+			assert.Equal(t, "id", user)
+			assert.Equal(t, "secret", pass)
+			_ = json.NewEncoder(w).Encode(map[string]string{"access_token": "token"})
+		case "/api/beta/networks/page":
+			assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+			if atomic.AddInt32(&calls, 1) == 1 {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(cloudconnexa.NetworkPageResponse{
+				Content:    []cloudconnexa.Network{{Id: "network-id", Name: "network"}},
+				TotalPages: 1,
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	c, err := newClient(context.Background(), server.URL, "id", "secret", newTestRetryTransport(3))
+	require.NoError(t, err)
+	assert.Equal(t, "token", c.client.Token)
+
+	network, err := c.client.Networks.Get("network-id")
+	require.NoError(t, err)
+	require.NotNil(t, network)
+	assert.Equal(t, "network", network.Name)
+}
+
+func TestNewClient_timeoutAppliesToEachAttempt(t *testing.T) {
+	server, calls := newThrottlingServer(t, 1, http.StatusTooManyRequests, "1")
+	transport := &retryTransport{
+		next:       &timeoutTransport{next: http.DefaultTransport, timeout: 500 * time.Millisecond},
+		maxRetries: 1,
+		minWait:    time.Millisecond,
+		maxWait:    5 * time.Second,
+	}
+	c, err := newClient(context.Background(), server.URL, "id", "secret", transport)
+	require.NoError(t, err)
+
+	// The wait requested by the API is longer than the timeout of an attempt.
+	start := time.Now()
+	resp, err := c.httpClient.Get(server.URL + "/api/beta/networks/page")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.GreaterOrEqual(t, time.Since(start), time.Second)
+	assert.EqualValues(t, 2, atomic.LoadInt32(calls))
+}
+
+func TestTimeoutTransport_boundsEachAttempt(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+	client := &http.Client{Transport: &timeoutTransport{next: http.DefaultTransport, timeout: 50 * time.Millisecond}}
+
+	start := time.Now()
+	_, err := client.Get(server.URL)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no response from the Cloud Connexa API within 50ms")
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestNewClient_requiresCredentials(t *testing.T) {
+	_, err := newClient(context.Background(), "http://localhost", "", "secret", http.DefaultTransport)
+	assert.ErrorIs(t, err, cloudconnexa.ErrCredentialsRequired)
+}
+
+func TestRateLimitTransport_sharesBucketAcrossCalls(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/beta/oauth/token":
+			_ = json.NewEncoder(w).Encode(map[string]string{"access_token": "token"})
+		case "/api/beta/connectors/page":
+			atomic.AddInt32(&requests, 1)
+			_ = json.NewEncoder(w).Encode(cloudconnexa.ConnectorPageResponse{
+				Content: []cloudconnexa.Connector{{Id: "connector-id", Name: "connector"}},
+			})
+		case "/api/beta/connectors/connector-id/profile":
+			atomic.AddInt32(&requests, 1)
+			_, _ = w.Write([]byte("profile"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	limiter := newRateLimiter(20, 1)
+	c, err := newClient(context.Background(), server.URL, "id", "secret", &rateLimitTransport{next: http.DefaultTransport, limiter: limiter})
+	require.NoError(t, err)
+
+	start := time.Now()
+	done := make(chan error)
+	for i := 0; i < 4; i++ {
+		go func() {
+			_, err := c.client.Connectors.GetByID("connector-id")
+			if err == nil {
+				_, err = c.client.Connectors.GetProfile("connector-id")
+			}
+			done <- err
+		}()
+	}
+	for i := 0; i < 4; i++ {
+		require.NoError(t, <-done)
+	}
+
+	// The token request consumed the only burst token, so each of the 8 API calls
+	// had to wait for a new one at 20 requests per second.
+	assert.EqualValues(t, 8, atomic.LoadInt32(&requests))
+	assert.GreaterOrEqual(t, time.Since(start), 350*time.Millisecond)
+}
+
+func TestRateLimitTransport_queuesMoreCallersThanBurst(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/beta/oauth/token" {
+			_ = json.NewEncoder(w).Encode(map[string]string{"access_token": "token"})
+			return
+		}
+		atomic.AddInt32(&calls, 1)
+		_ = json.NewEncoder(w).Encode(cloudconnexa.NetworkPageResponse{TotalPages: 1})
+	}))
+	defer server.Close()
+	// Every attempt times out much sooner than the last caller gets a token.
+	c, err := newClient(context.Background(), server.URL, "id", "secret", &rateLimitTransport{
+		next:    &timeoutTransport{next: http.DefaultTransport, timeout: 100 * time.Millisecond},
+		limiter: newRateLimiter(20, 2),
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	const callers = 12
+	start := time.Now()
+	done := make(chan error)
+	for i := 0; i < callers; i++ {
+		go func() {
+			_, err := c.withContext(ctx).Networks.List()
+			done <- err
+		}()
+	}
+	for i := 0; i < callers; i++ {
+		assert.NoError(t, <-done)
+	}
+	assert.GreaterOrEqual(t, atomic.LoadInt32(&calls), int32(callers))
+	assert.GreaterOrEqual(t, time.Since(start), 450*time.Millisecond)
+
+	// Only the timeout of the operation bounds the wait.
+	short, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	for i := 0; i < callers; i++ {
+		go func() {
+			_, err := c.withContext(short).Networks.List()
+			done <- err
+		}()
+	}
+	var throttled int
+	for i := 0; i < callers; i++ {
+		if err := <-done; err != nil && strings.Contains(err.Error(), "requests_per_second") {
+			throttled++
+		}
+	}
+	assert.Greater(t, throttled, 0)
+}
+
+func TestNewRateLimiter(t *testing.T) {
+	assert.Equal(t, rate.Inf, newRateLimiter(0, 5).Limit())
+	limiter := newRateLimiter(2.5, 3)
+	assert.Equal(t, rate.Limit(2.5), limiter.Limit())
+	assert.Equal(t, 3, limiter.Burst())
+}
+
+func TestApiClient_withContextPropagatesDeadline(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/beta/oauth/token" {
+			_ = json.NewEncoder(w).Encode(map[string]string{"access_token": "token"})
+			return
+		}
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	c, err := newClient(context.Background(), server.URL, "id", "secret", newTestRetryTransport(0))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = c.withContext(ctx).Networks.List()
+	require.Error(t, err)
+	assert.Less(t, time.Since(start), 5*time.Second)
+
+	diags := diagFromErr(ctx, err)
+	require.Len(t, diags, 1)
+	assert.Equal(t, "Timed out waiting for the Cloud Connexa API", diags[0].Summary)
+	assert.Contains(t, diags[0].Detail, "`timeouts` block")
+}
+
+func TestApiClient_withContextKeepsToken(t *testing.T) {
+	server, _ := newThrottlingServer(t, 0, http.StatusOK, "")
+	c, err := newClient(context.Background(), server.URL, "id", "secret", newTestRetryTransport(0))
+	require.NoError(t, err)
+
+	bound := c.withContext(context.Background())
+	assert.Equal(t, c.client.Token, bound.Token)
+	assert.Equal(t, c.client.BaseURL, bound.BaseURL)
+	assert.NotSame(t, c.client, bound)
+}
@@ -1,13 +1,13 @@
 package e2e
 
 import (
-	"fmt"
 	"github.com/OpenVPN/terraform-provider-openvpn-cloud/cloudconnexa"
 	"github.com/gruntwork-io/terratest/modules/terraform"
 	api "github.com/openvpn/cloudconnexa-go-client/v2/cloudconnexa"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"os"
+	"strings"
 	"testing"
 	"time"
 )
@@ -70,13 +70,16 @@ func TestCreationDeletion(t *testing.T) {
 	assert.True(t, connectorWasOnline)
 }
 
+// validateEnvVars skips the test unless it can reach a live tenant. It deploys a
+// connector on AWS, so it cannot run against the fake API like the acceptance tests.
 func validateEnvVars(t *testing.T) {
-	validateEnvVar(t, CloudConnexaHostKey)
-	validateEnvVar(t, cloudconnexa.ClientIDEnvVar)
-	validateEnvVar(t, cloudconnexa.ClientSecretEnvVar)
-}
-
-func validateEnvVar(t *testing.T, envVar string) {
-	fmt.Println(os.Getenv(envVar))
-	require.NotEmptyf(t, os.Getenv(envVar), "%s must be set for acceptance tests", envVar)
+	var missing []string
+	for _, envVar := range []string{CloudConnexaHostKey, cloudconnexa.ClientIDEnvVar, cloudconnexa.ClientSecretEnvVar} {
+		if os.Getenv(envVar) == "" {
+			missing = append(missing, envVar)
+		}
+	}
+	if len(missing) > 0 {
+		t.Skipf("%s must be set for the end-to-end test", strings.Join(missing, ", "))
+	}
 }
@@ -0,0 +1,1088 @@
+// Package fakeapi provides an in-memory stand-in for the Cloud Connexa API so that the
+// provider and client tests can run without credentials or network access.
+package fakeapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/openvpn/cloudconnexa-go-client/v2/cloudconnexa"
+)
+
+const (
+	DefaultClientID     = "fake-client-id"
+	DefaultClientSecret = "fake-client-secret"
+	AccessToken         = "fake-access-token"
+	DefaultUserGroup    = "Default"
+)
+
+// Regions are the VPN regions known to the fake server.
+var Regions = []cloudconnexa.VpnRegion{
+	{Id: "us-west-1", Continent: "North America", Country: "United States", CountryISO: "US", RegionName: "N. California"},
+	{Id: "us-east-1", Continent: "North America", Country: "United States", CountryISO: "US", RegionName: "N. Virginia"},
+	{Id: "eu-central-1", Continent: "Europe", Country: "Germany", CountryISO: "DE", RegionName: "Frankfurt"},
+	{Id: "fi-hel", Continent: "Europe", Country: "Finland", CountryISO: "FI", RegionName: "Helsinki"},
+	{Id: "it-mxp", Continent: "Europe", Country: "Italy", CountryISO: "IT", RegionName: "Milan"},
+}
+
+// Server is an httptest.Server that implements the subset of the Cloud Connexa API
+// used by the provider, keeping all objects in memory.
+type Server struct {
+	*httptest.Server
+
+	ClientID     string
+	ClientSecret string
+	// NetworkLimit is the number of networks the organization may have, or 0 for no
+	// limit.
+	NetworkLimit int
+	// ConnectorOnlineAfter is the number of times a connector is listed before it
+	// reports being online, as if it had been deployed in the meantime, or 0 for
+	// connectors that stay offline.
+	ConnectorOnlineAfter int
+
+	mu         sync.Mutex
+	requests   int
+	lastId     int
+	networks   []*cloudconnexa.Network
+	hosts      []*cloudconnexa.Host
+	connectors []*cloudconnexa.Connector
+	routes     []*cloudconnexa.Route
+	users      []*cloudconnexa.User
+	userGroups []*cloudconnexa.UserGroup
+	dnsRecords []*cloudconnexa.DnsRecord
+	ipServices []*cloudconnexa.IPServiceResponse
+
+	// connectorDescriptions holds the descriptions of the connectors, which the Connector
+	// type has no field for.
+	connectorDescriptions map[string]string
+	// connectorPolls counts the times each connector has been listed.
+	connectorPolls map[string]int
+}
+
+// NewServer starts a fake Cloud Connexa API that accepts DefaultClientID and
+// DefaultClientSecret. The caller must Close it.
+func NewServer() *Server {
+	s := NewUnstartedServer()
+	s.Start()
+	return s
+}
+
+// NewUnstartedServer returns a fake Cloud Connexa API that is not started yet, so that
+// its TLS configuration can be changed before calling Start or StartTLS.
+func NewUnstartedServer() *Server {
+	s := &Server{
+		ClientID:              DefaultClientID,
+		ClientSecret:          DefaultClientSecret,
+		connectorDescriptions: map[string]string{},
+		connectorPolls:        map[string]int{},
+	}
+	s.userGroups = append(s.userGroups, &cloudconnexa.UserGroup{
+		ID:             s.newId(),
+		Name:           DefaultUserGroup,
+		ConnectAuth:    "AUTO",
+		InternetAccess: "LOCAL",
+		MaxDevice:      3,
+		SystemSubnets:  []string{"100.96.0.0/11"},
+		VpnRegionIds:   []string{Regions[0].Id},
+	})
+	s.Server = httptest.NewUnstartedServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Requests returns the number of requests the server has received, token requests
+// included.
+func (s *Server) Requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+type route struct {
+	method  string
+	pattern *regexp.Regexp
+	handler func(w http.ResponseWriter, r *http.Request, params []string)
+}
+
+func (s *Server) routeTable() []route {
+	const id = `([^/]+)`
+	return []route{
+		{http.MethodGet, regexp.MustCompile(`^/api/beta/networks/page$`), s.listNetworks},
+		{http.MethodPost, regexp.MustCompile(`^/api/beta/networks$`), s.createNetwork},
+		{http.MethodPut, regexp.MustCompile(`^/api/beta/networks/` + id + `$`), s.updateNetwork},
+		{http.MethodDelete, regexp.MustCompile(`^/api/beta/networks/` + id + `$`), s.deleteNetwork},
+		{http.MethodGet, regexp.MustCompile(`^/api/beta/networks/` + id + `/routes/page$`), s.listRoutes},
+		{http.MethodPost, regexp.MustCompile(`^/api/beta/networks/` + id + `/routes$`), s.createRoute},
+		{http.MethodPut, regexp.MustCompile(`^/api/beta/networks/` + id + `/routes/` + id + `$`), s.updateRoute},
+		{http.MethodDelete, regexp.MustCompile(`^/api/beta/networks/` + id + `/routes/` + id + `$`), s.deleteRoute},
+		{http.MethodGet, regexp.MustCompile(`^/api/beta/hosts/page$`), s.listHosts},
+		{http.MethodPost, regexp.MustCompile(`^/api/beta/hosts$`), s.createHost},
+		{http.MethodPut, regexp.MustCompile(`^/api/beta/hosts/` + id + `$`), s.updateHost},
+		{http.MethodDelete, regexp.MustCompile(`^/api/beta/hosts/` + id + `$`), s.deleteHost},
+		{http.MethodGet, regexp.MustCompile(`^/api/beta/connectors/page$`), s.listConnectors},
+		{http.MethodPost, regexp.MustCompile(`^/api/beta/connectors$`), s.createConnector},
+		{http.MethodPut, regexp.MustCompile(`^/api/beta/connectors/` + id + `$`), s.updateConnector},
+		{http.MethodDelete, regexp.MustCompile(`^/api/beta/connectors/` + id + `$`), s.deleteConnector},
+		{http.MethodPost, regexp.MustCompile(`^/api/beta/connectors/` + id + `/profile$`), s.connectorProfile},
+		{http.MethodGet, regexp.MustCompile(`^/api/beta/users/page$`), s.listUsers},
+		{http.MethodPost, regexp.MustCompile(`^/api/beta/users$`), s.createUser},
+		{http.MethodPut, regexp.MustCompile(`^/api/beta/users/` + id + `$`), s.updateUser},
+		{http.MethodDelete, regexp.MustCompile(`^/api/beta/users/` + id + `$`), s.deleteUser},
+		{http.MethodGet, regexp.MustCompile(`^/api/beta/user-groups/page$`), s.listUserGroups},
+		{http.MethodPost, regexp.MustCompile(`^/api/beta/user-groups$`), s.createUserGroup},
+		{http.MethodPut, regexp.MustCompile(`^/api/beta/user-groups/` + id + `$`), s.updateUserGroup},
+		{http.MethodDelete, regexp.MustCompile(`^/api/beta/user-groups/` + id + `$`), s.deleteUserGroup},
+		{http.MethodGet, regexp.MustCompile(`^/api/beta/dns-records/page$`), s.listDnsRecords},
+		{http.MethodPost, regexp.MustCompile(`^/api/beta/dns-records$`), s.createDnsRecord},
+		{http.MethodPut, regexp.MustCompile(`^/api/beta/dns-records/` + id + `$`), s.updateDnsRecord},
+		{http.MethodDelete, regexp.MustCompile(`^/api/beta/dns-records/` + id + `$`), s.deleteDnsRecord},
+		{http.MethodGet, regexp.MustCompile(`^/api/beta/ip-services/page$`), s.listIPServices},
+		{http.MethodGet, regexp.MustCompile(`^/api/beta/ip-services/single$`), s.getIPService},
+		{http.MethodPost, regexp.MustCompile(`^/api/beta/ip-services$`), s.createIPService},
+		{http.MethodPut, regexp.MustCompile(`^/api/beta/ip-services/` + id + `$`), s.updateIPService},
+		{http.MethodDelete, regexp.MustCompile(`^/api/beta/ip-services/` + id + `$`), s.deleteIPService},
+		{http.MethodGet, regexp.MustCompile(`^/api/beta/regions$`), s.listRegions},
+	}
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests++
+	w.Header().Set("X-Request-Id", fmt.Sprintf("fake-request-%d", s.requests))
+	s.mu.Unlock()
+	if r.URL.Path == "/api/beta/oauth/token" {
+		s.token(w, r)
+		return
+	}
+	if r.Header.Get("Authorization") != "Bearer "+AccessToken {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "a valid bearer token is required")
+		return
+	}
+	pathMatched := false
+	for _, rt := range s.routeTable() {
+		params := rt.pattern.FindStringSubmatch(r.URL.Path)
+		if params == nil {
+			continue
+		}
+		pathMatched = true
+		if rt.method != r.Method {
+			continue
+		}
+		s.mu.Lock()
+		rt.handler(w, r, params[1:])
+		s.mu.Unlock()
+		return
+	}
+	if pathMatched {
+		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", fmt.Sprintf("%s is not supported on %s", r.Method, r.URL.Path))
+		return
+	}
+	writeError(w, http.StatusNotFound, "NOT_FOUND", fmt.Sprintf("no endpoint for %s", r.URL.Path))
+}
+
+func (s *Server) token(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "the token endpoint only accepts POST")
+		return
+	}
+	clientId, clientSecret, ok := r.BasicAuth()
+	if !ok || clientId != s.ClientID || clientSecret != s.ClientSecret {
+		writeError(w, http.StatusUnauthorized, "INVALID_CLIENT", "invalid client credentials")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": AccessToken,
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+	})
+}
+
+func (s *Server) newId() string {
+	s.lastId++
+	return fmt.Sprintf("00000000-0000-4000-8000-%012d", s.lastId)
+}
+
+// systemSubnets returns deterministic subnets for the n-th network or host.
+func systemSubnets(n int) []string {
+	return []string{fmt.Sprintf("100.%d.%d.0/24", 64+n/256%64, n%256), fmt.Sprintf("fd00:%x::/64", n)}
+}
+
+func (s *Server) findNetwork(id string) *cloudconnexa.Network {
+	for _, n := range s.networks {
+		if n.Id == id {
+			return n
+		}
+	}
+	return nil
+}
+
+func (s *Server) findHost(id string) *cloudconnexa.Host {
+	for _, h := range s.hosts {
+		if h.Id == id {
+			return h
+		}
+	}
+	return nil
+}
+
+func (s *Server) networkItemExists(itemType, itemId string) bool {
+	switch itemType {
+	case "NETWORK":
+		return s.findNetwork(itemId) != nil
+	case "HOST":
+		return s.findHost(itemId) != nil
+	}
+	return false
+}
+
+func regionExists(id string) bool {
+	for _, r := range Regions {
+		if r.Id == id {
+			return true
+		}
+	}
+	return false
+}
+
+// networkView returns a copy of the network with its current connectors and routes.
+func (s *Server) networkView(n *cloudconnexa.Network) cloudconnexa.Network {
+	view := *n
+	view.Connectors = []cloudconnexa.NetworkConnector{}
+	for _, c := range s.connectors {
+		if c.NetworkItemId == n.Id {
+			view.Connectors = append(view.Connectors, cloudconnexa.NetworkConnector{
+				Id:              c.Id,
+				Name:            c.Name,
+				Description:     s.connectorDescriptions[c.Id],
+				NetworkItemId:   c.NetworkItemId,
+				NetworkItemType: c.NetworkItemType,
+				VpnRegionId:     c.VpnRegionId,
+				IPv4Address:     c.IPv4Address,
+				IPv6Address:     c.IPv6Address,
+			})
+		}
+	}
+	view.Routes = []cloudconnexa.Route{}
+	for _, r := range s.routes {
+		if r.NetworkItemId == n.Id {
+			view.Routes = append(view.Routes, *r)
+		}
+	}
+	return view
+}
+
+func (s *Server) hostView(h *cloudconnexa.Host) cloudconnexa.Host {
+	view := *h
+	view.Connectors = []cloudconnexa.Connector{}
+	for _, c := range s.connectors {
+		if c.NetworkItemId == h.Id {
+			view.Connectors = append(view.Connectors, *c)
+		}
+	}
+	return view
+}
+
+func (s *Server) addConnector(name, regionId, itemType, itemId string) (*cloudconnexa.Connector, error) {
+	if name == "" {
+		return nil, fmt.Errorf("connector name must not be empty")
+	}
+	if !regionExists(regionId) {
+		return nil, fmt.Errorf("unknown VPN region %q", regionId)
+	}
+	n := s.lastId + 1
+	c := &cloudconnexa.Connector{
+		Id:               s.newId(),
+		Name:             name,
+		NetworkItemId:    itemId,
+		NetworkItemType:  itemType,
+		VpnRegionId:      regionId,
+		IPv4Address:      fmt.Sprintf("100.96.%d.%d", n/256%256, n%256),
+		IPv6Address:      fmt.Sprintf("fd00:a:b:c::%x", n),
+		ConnectionStatus: "offline",
+	}
+	s.connectors = append(s.connectors, c)
+	return c, nil
+}
+
+func (s *Server) removeConnectorsOf(itemId string) {
+	connectors := s.connectors[:0]
+	for _, c := range s.connectors {
+		if c.NetworkItemId != itemId {
+			connectors = append(connectors, c)
+		}
+	}
+	s.connectors = connectors
+}
+
+func (s *Server) removeRoutesOf(itemId string) {
+	routes := s.routes[:0]
+	for _, r := range s.routes {
+		if r.NetworkItemId != itemId {
+			routes = append(routes, r)
+		}
+	}
+	s.routes = routes
+}
+
+func (s *Server) removeIPServicesOf(itemId string) {
+	services := s.ipServices[:0]
+	for _, svc := range s.ipServices {
+		if svc.NetworkItemId != itemId {
+			services = append(services, svc)
+		}
+	}
+	s.ipServices = services
+}
+
+func (s *Server) listNetworks(w http.ResponseWriter, r *http.Request, _ []string) {
+	views := make([]cloudconnexa.Network, len(s.networks))
+	for i, n := range s.networks {
+		views[i] = s.networkView(n)
+	}
+	writePage(w, r, views)
+}
+
+func (s *Server) createNetwork(w http.ResponseWriter, r *http.Request, _ []string) {
+	var n cloudconnexa.Network
+	if !readJSON(w, r, &n) {
+		return
+	}
+	if n.Name == "" {
+		writeFieldError(w, http.StatusBadRequest, "VALIDATION_ERROR", "name", "name must not be empty")
+		return
+	}
+	if len(n.Connectors) == 0 {
+		writeFieldError(w, http.StatusBadRequest, "VALIDATION_ERROR", "connectors", "at least one connector is required")
+		return
+	}
+	for i, c := range n.Connectors {
+		if !regionExists(c.VpnRegionId) {
+			writeFieldError(w, http.StatusBadRequest, "INVALID_REGION", fmt.Sprintf("connectors[%d].vpnRegionId", i), fmt.Sprintf("unknown VPN region %q", c.VpnRegionId))
+			return
+		}
+	}
+	for _, existing := range s.networks {
+		if existing.Name == n.Name {
+			writeError(w, http.StatusConflict, "DUPLICATE_NAME", fmt.Sprintf("a network named %q already exists", n.Name))
+			return
+		}
+	}
+	if s.NetworkLimit > 0 && len(s.networks) >= s.NetworkLimit {
+		writeError(w, http.StatusBadRequest, "QUOTA_EXCEEDED", fmt.Sprintf("the organization may not have more than %d networks", s.NetworkLimit))
+		return
+	}
+	network := &cloudconnexa.Network{
+		Id:             s.newId(),
+		Name:           n.Name,
+		Description:    n.Description,
+		Egress:         n.Egress,
+		InternetAccess: n.InternetAccess,
+		SystemSubnets:  systemSubnets(len(s.networks) + len(s.hosts) + 1),
+	}
+	s.networks = append(s.networks, network)
+	for _, c := range n.Connectors {
+		connector, err := s.addConnector(c.Name, c.VpnRegionId, "NETWORK", network.Id)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+			return
+		}
+		s.connectorDescriptions[connector.Id] = c.Description
+	}
+	writeJSON(w, http.StatusCreated, s.networkView(network))
+}
+
+func (s *Server) updateNetwork(w http.ResponseWriter, r *http.Request, params []string) {
+	network := s.findNetwork(params[0])
+	if network == nil {
+		writeNotFound(w, "network", params[0])
+		return
+	}
+	var n cloudconnexa.Network
+	if !readJSON(w, r, &n) {
+		return
+	}
+	network.Name = n.Name
+	network.Description = n.Description
+	network.Egress = n.Egress
+	network.InternetAccess = n.InternetAccess
+	writeJSON(w, http.StatusOK, s.networkView(network))
+}
+
+func (s *Server) deleteNetwork(w http.ResponseWriter, _ *http.Request, params []string) {
+	for i, n := range s.networks {
+		if n.Id == params[0] {
+			s.networks = append(s.networks[:i], s.networks[i+1:]...)
+			s.removeConnectorsOf(n.Id)
+			s.removeRoutesOf(n.Id)
+			s.removeIPServicesOf(n.Id)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+	writeNotFound(w, "network", params[0])
+}
+
+func (s *Server) listRoutes(w http.ResponseWriter, r *http.Request, params []string) {
+	if s.findNetwork(params[0]) == nil {
+		writeNotFound(w, "network", params[0])
+		return
+	}
+	var routes []routeResponse
+	for _, rt := range s.routes {
+		if rt.NetworkItemId == params[0] {
+			routes = append(routes, newRouteResponse(rt))
+		}
+	}
+	writePage(w, r, routes)
+}
+
+// routeResponse is a route as the API returns it: the value of domain routes is
+// returned as their domain rather than their subnet.
+type routeResponse struct {
+	cloudconnexa.Route
+	Domain string `json:"domain,omitempty"`
+}
+
+func newRouteResponse(rt *cloudconnexa.Route) routeResponse {
+	response := routeResponse{Route: *rt}
+	if rt.Type == "DOMAIN" {
+		response.Domain = rt.Subnet
+		response.Subnet = ""
+	}
+	return response
+}
+
+// routeType infers the route type from its value the same way the API does.
+func routeType(value string) (string, bool) {
+	if _, prefix, err := net.ParseCIDR(value); err == nil {
+		if prefix.IP.To4() != nil {
+			return "IP_V4", true
+		}
+		return "IP_V6", true
+	}
+	if value != "" && !strings.ContainsAny(value, "/ ") {
+		return "DOMAIN", true
+	}
+	return "", false
+}
+
+// canonicalSubnet returns the network of a CIDR, which the API stores instead of the
+// value it was given. Other values are returned unchanged.
+func canonicalSubnet(value string) string {
+	if _, prefix, err := net.ParseCIDR(value); err == nil {
+		return prefix.String()
+	}
+	return value
+}
+
+func (s *Server) createRoute(w http.ResponseWriter, r *http.Request, params []string) {
+	if s.findNetwork(params[0]) == nil {
+		writeNotFound(w, "network", params[0])
+		return
+	}
+	var body struct {
+		Description string `json:"description"`
+		Value       string `json:"value"`
+	}
+	if !readJSON(w, r, &body) {
+		return
+	}
+	typ, ok := routeType(body.Value)
+	if !ok {
+		writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", fmt.Sprintf("invalid route value %q", body.Value))
+		return
+	}
+	rt := &cloudconnexa.Route{
+		Id:            s.newId(),
+		Type:          typ,
+		Subnet:        canonicalSubnet(body.Value),
+		Description:   body.Description,
+		NetworkItemId: params[0],
+	}
+	s.routes = append(s.routes, rt)
+	writeJSON(w, http.StatusCreated, newRouteResponse(rt))
+}
+
+func (s *Server) findRoute(networkId, routeId string) *cloudconnexa.Route {
+	for _, rt := range s.routes {
+		if rt.NetworkItemId == networkId && rt.Id == routeId {
+			return rt
+		}
+	}
+	return nil
+}
+
+func (s *Server) updateRoute(w http.ResponseWriter, r *http.Request, params []string) {
+	rt := s.findRoute(params[0], params[1])
+	if rt == nil {
+		writeNotFound(w, "route", params[1])
+		return
+	}
+	var body struct {
+		Description string `json:"description"`
+		Value       string `json:"value"`
+	}
+	if !readJSON(w, r, &body) {
+		return
+	}
+	rt.Description = body.Description
+	if body.Value != "" {
+		typ, ok := routeType(body.Value)
+		if !ok || typ != rt.Type {
+			writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", fmt.Sprintf("invalid %s route value %q", rt.Type, body.Value))
+			return
+		}
+		rt.Subnet = canonicalSubnet(body.Value)
+	}
+	writeJSON(w, http.StatusOK, newRouteResponse(rt))
+}
+
+func (s *Server) deleteRoute(w http.ResponseWriter, _ *http.Request, params []string) {
+	for i, rt := range s.routes {
+		if rt.NetworkItemId == params[0] && rt.Id == params[1] {
+			s.routes = append(s.routes[:i], s.routes[i+1:]...)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+	writeNotFound(w, "route", params[1])
+}
+
+func (s *Server) listHosts(w http.ResponseWriter, r *http.Request, _ []string) {
+	views := make([]cloudconnexa.Host, len(s.hosts))
+	for i, h := range s.hosts {
+		views[i] = s.hostView(h)
+	}
+	writePage(w, r, views)
+}
+
+func (s *Server) createHost(w http.ResponseWriter, r *http.Request, _ []string) {
+	var h cloudconnexa.Host
+	if !readJSON(w, r, &h) {
+		return
+	}
+	if h.Name == "" {
+		writeFieldError(w, http.StatusBadRequest, "VALIDATION_ERROR", "name", "name must not be empty")
+		return
+	}
+	for i, c := range h.Connectors {
+		if !regionExists(c.VpnRegionId) {
+			writeFieldError(w, http.StatusBadRequest, "INVALID_REGION", fmt.Sprintf("connectors[%d].vpnRegionId", i), fmt.Sprintf("unknown VPN region %q", c.VpnRegionId))
+			return
+		}
+	}
+	host := &cloudconnexa.Host{
+		Id:             s.newId(),
+		Name:           h.Name,
+		Description:    h.Description,
+		InternetAccess: h.InternetAccess,
+		SystemSubnets:  systemSubnets(len(s.networks) + len(s.hosts) + 1),
+	}
+	s.hosts = append(s.hosts, host)
+	for _, c := range h.Connectors {
+		if _, err := s.addConnector(c.Name, c.VpnRegionId, "HOST", host.Id); err != nil {
+			writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+			return
+		}
+	}
+	writeJSON(w, http.StatusCreated, s.hostView(host))
+}
+
+func (s *Server) updateHost(w http.ResponseWriter, r *http.Request, params []string) {
+	host := s.findHost(params[0])
+	if host == nil {
+		writeNotFound(w, "host", params[0])
+		return
+	}
+	var h cloudconnexa.Host
+	if !readJSON(w, r, &h) {
+		return
+	}
+	host.Name = h.Name
+	host.Description = h.Description
+	host.InternetAccess = h.InternetAccess
+	writeJSON(w, http.StatusOK, s.hostView(host))
+}
+
+func (s *Server) deleteHost(w http.ResponseWriter, _ *http.Request, params []string) {
+	for i, h := range s.hosts {
+		if h.Id == params[0] {
+			s.hosts = append(s.hosts[:i], s.hosts[i+1:]...)
+			s.removeConnectorsOf(h.Id)
+			s.removeIPServicesOf(h.Id)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+	writeNotFound(w, "host", params[0])
+}
+
+// connectorResponse is a connector as the API returns it, along with its description.
+type connectorResponse struct {
+	cloudconnexa.Connector
+	Description string `json:"description"`
+}
+
+func (s *Server) newConnectorResponse(c *cloudconnexa.Connector) connectorResponse {
+	return connectorResponse{Connector: *c, Description: s.connectorDescriptions[c.Id]}
+}
+
+// connectorRequest is the body of the requests that create and update connectors.
+type connectorRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	VpnRegionId string `json:"vpnRegionId"`
+}
+
+func (s *Server) listConnectors(w http.ResponseWriter, r *http.Request, _ []string) {
+	connectors := make([]connectorResponse, len(s.connectors))
+	for i, c := range s.connectors {
+		s.connectorPolls[c.Id]++
+		if s.ConnectorOnlineAfter > 0 && s.connectorPolls[c.Id] >= s.ConnectorOnlineAfter {
+			c.ConnectionStatus = "online"
+		}
+		connectors[i] = s.newConnectorResponse(c)
+	}
+	writePage(w, r, connectors)
+}
+
+func (s *Server) createConnector(w http.ResponseWriter, r *http.Request, _ []string) {
+	itemId := r.URL.Query().Get("networkItemId")
+	itemType := r.URL.Query().Get("networkItemType")
+	if !s.networkItemExists(itemType, itemId) {
+		writeNotFound(w, strings.ToLower(itemType), itemId)
+		return
+	}
+	var body connectorRequest
+	if !readJSON(w, r, &body) {
+		return
+	}
+	c, err := s.addConnector(body.Name, body.VpnRegionId, itemType, itemId)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+		return
+	}
+	s.connectorDescriptions[c.Id] = body.Description
+	writeJSON(w, http.StatusCreated, s.newConnectorResponse(c))
+}
+
+func (s *Server) updateConnector(w http.ResponseWriter, r *http.Request, params []string) {
+	for _, c := range s.connectors {
+		if c.Id != params[0] || c.NetworkItemId != r.URL.Query().Get("networkItemId") {
+			continue
+		}
+		var body connectorRequest
+		if !readJSON(w, r, &body) {
+			return
+		}
+		if body.Name == "" {
+			writeFieldError(w, http.StatusBadRequest, "VALIDATION_ERROR", "name", "connector name must not be empty")
+			return
+		}
+		if !regionExists(body.VpnRegionId) {
+			writeFieldError(w, http.StatusBadRequest, "INVALID_REGION", "vpnRegionId", fmt.Sprintf("unknown VPN region %q", body.VpnRegionId))
+			return
+		}
+		c.Name = body.Name
+		c.VpnRegionId = body.VpnRegionId
+		s.connectorDescriptions[c.Id] = body.Description
+		writeJSON(w, http.StatusOK, s.newConnectorResponse(c))
+		return
+	}
+	writeNotFound(w, "connector", params[0])
+}
+
+func (s *Server) deleteConnector(w http.ResponseWriter, r *http.Request, params []string) {
+	for i, c := range s.connectors {
+		if c.Id == params[0] && c.NetworkItemId == r.URL.Query().Get("networkItemId") {
+			s.connectors = append(s.connectors[:i], s.connectors[i+1:]...)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+	writeNotFound(w, "connector", params[0])
+}
+
+func (s *Server) connectorProfile(w http.ResponseWriter, _ *http.Request, params []string) {
+	for _, c := range s.connectors {
+		if c.Id == params[0] {
+			w.Header().Set("Content-Type", "text/plain")
+			_, _ = fmt.Fprintf(w, "client\ndev tun\nremote %s.fake.openvpn.com 1194 udp\n# connector %s\n<key>\nfake-private-key-%s\n</key>\n", c.VpnRegionId, c.Id, c.Id)
+			return
+		}
+	}
+	writeNotFound(w, "connector", params[0])
+}
+
+func (s *Server) listUsers(w http.ResponseWriter, r *http.Request, _ []string) {
+	users := make([]cloudconnexa.User, len(s.users))
+	for i, u := range s.users {
+		users[i] = *u
+	}
+	writePage(w, r, users)
+}
+
+func (s *Server) defaultGroupId() string {
+	for _, g := range s.userGroups {
+		if g.Name == DefaultUserGroup {
+			return g.ID
+		}
+	}
+	return ""
+}
+
+func (s *Server) createUser(w http.ResponseWriter, r *http.Request, _ []string) {
+	var u cloudconnexa.User
+	if !readJSON(w, r, &u) {
+		return
+	}
+	if u.Username == "" || u.Email == "" {
+		writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", "username and email must not be empty")
+		return
+	}
+	for _, existing := range s.users {
+		if existing.Username == u.Username {
+			writeError(w, http.StatusConflict, "DUPLICATE_USERNAME", fmt.Sprintf("user %q already exists", u.Username))
+			return
+		}
+	}
+	u.Id = s.newId()
+	u.AuthType = "LOCAL"
+	u.Status = "INVITED"
+	// The API always creates users as members.
+	u.Role = "MEMBER"
+	if u.GroupId == "" {
+		u.GroupId = s.defaultGroupId()
+	}
+	for i := range u.Devices {
+		u.Devices[i].Id = s.newId()
+	}
+	s.users = append(s.users, &u)
+	writeJSON(w, http.StatusCreated, u)
+}
+
+func (s *Server) updateUser(w http.ResponseWriter, r *http.Request, params []string) {
+	for _, u := range s.users {
+		if u.Id == params[0] {
+			var body cloudconnexa.User
+			if !readJSON(w, r, &body) {
+				return
+			}
+			u.Email = body.Email
+			u.FirstName = body.FirstName
+			u.LastName = body.LastName
+			if body.GroupId != "" {
+				u.GroupId = body.GroupId
+			}
+			if body.Status != "" {
+				u.Status = body.Status
+			}
+			writeJSON(w, http.StatusOK, u)
+			return
+		}
+	}
+	writeNotFound(w, "user", params[0])
+}
+
+func (s *Server) deleteUser(w http.ResponseWriter, _ *http.Request, params []string) {
+	for i, u := range s.users {
+		if u.Id == params[0] {
+			s.users = append(s.users[:i], s.users[i+1:]...)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+	writeNotFound(w, "user", params[0])
+}
+
+func (s *Server) listUserGroups(w http.ResponseWriter, r *http.Request, _ []string) {
+	groups := make([]cloudconnexa.UserGroup, len(s.userGroups))
+	for i, g := range s.userGroups {
+		groups[i] = *g
+	}
+	writePage(w, r, groups)
+}
+
+func (s *Server) createUserGroup(w http.ResponseWriter, r *http.Request, _ []string) {
+	var g cloudconnexa.UserGroup
+	if !readJSON(w, r, &g) {
+		return
+	}
+	if g.Name == "" {
+		writeFieldError(w, http.StatusBadRequest, "VALIDATION_ERROR", "name", "name must not be empty")
+		return
+	}
+	for i, region := range g.VpnRegionIds {
+		if !regionExists(region) {
+			writeFieldError(w, http.StatusBadRequest, "INVALID_REGION", fmt.Sprintf("vpnRegionIds[%d]", i), fmt.Sprintf("unknown VPN region %q", region))
+			return
+		}
+	}
+	g.ID = s.newId()
+	if len(g.SystemSubnets) == 0 {
+		g.SystemSubnets = []string{fmt.Sprintf("100.%d.0.0/16", 96+len(s.userGroups)%32)}
+	}
+	for i, subnet := range g.SystemSubnets {
+		g.SystemSubnets[i] = canonicalSubnet(subnet)
+	}
+	s.userGroups = append(s.userGroups, &g)
+	writeJSON(w, http.StatusCreated, g)
+}
+
+func (s *Server) updateUserGroup(w http.ResponseWriter, r *http.Request, params []string) {
+	for _, g := range s.userGroups {
+		if g.ID == params[0] {
+			var body cloudconnexa.UserGroup
+			if !readJSON(w, r, &body) {
+				return
+			}
+			body.ID = g.ID
+			if len(body.SystemSubnets) == 0 {
+				body.SystemSubnets = g.SystemSubnets
+			}
+			for i, subnet := range body.SystemSubnets {
+				body.SystemSubnets[i] = canonicalSubnet(subnet)
+			}
+			*g = body
+			writeJSON(w, http.StatusOK, g)
+			return
+		}
+	}
+	writeNotFound(w, "user group", params[0])
+}
+
+func (s *Server) deleteUserGroup(w http.ResponseWriter, _ *http.Request, params []string) {
+	for i, g := range s.userGroups {
+		if g.ID == params[0] {
+			s.userGroups = append(s.userGroups[:i], s.userGroups[i+1:]...)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+	writeNotFound(w, "user group", params[0])
+}
+
+func (s *Server) listDnsRecords(w http.ResponseWriter, r *http.Request, _ []string) {
+	records := make([]cloudconnexa.DnsRecord, len(s.dnsRecords))
+	for i, d := range s.dnsRecords {
+		records[i] = *d
+	}
+	writePage(w, r, records)
+}
+
+func (s *Server) createDnsRecord(w http.ResponseWriter, r *http.Request, _ []string) {
+	var d cloudconnexa.DnsRecord
+	if !readJSON(w, r, &d) {
+		return
+	}
+	if d.Domain == "" {
+		writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", "domain must not be empty")
+		return
+	}
+	d.Id = s.newId()
+	s.dnsRecords = append(s.dnsRecords, &d)
+	writeJSON(w, http.StatusCreated, d)
+}
+
+func (s *Server) updateDnsRecord(w http.ResponseWriter, r *http.Request, params []string) {
+	for _, d := range s.dnsRecords {
+		if d.Id == params[0] {
+			var body cloudconnexa.DnsRecord
+			if !readJSON(w, r, &body) {
+				return
+			}
+			body.Id = d.Id
+			*d = body
+			writeJSON(w, http.StatusOK, d)
+			return
+		}
+	}
+	writeNotFound(w, "DNS record", params[0])
+}
+
+func (s *Server) deleteDnsRecord(w http.ResponseWriter, _ *http.Request, params []string) {
+	for i, d := range s.dnsRecords {
+		if d.Id == params[0] {
+			s.dnsRecords = append(s.dnsRecords[:i], s.dnsRecords[i+1:]...)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+	writeNotFound(w, "DNS record", params[0])
+}
+
+func (s *Server) listIPServices(w http.ResponseWriter, r *http.Request, _ []string) {
+	services := make([]cloudconnexa.IPServiceResponse, len(s.ipServices))
+	for i, svc := range s.ipServices {
+		services[i] = *svc
+	}
+	writePage(w, r, services)
+}
+
+func (s *Server) getIPService(w http.ResponseWriter, r *http.Request, _ []string) {
+	id := r.URL.Query().Get("serviceId")
+	for _, svc := range s.ipServices {
+		if svc.Id == id {
+			writeJSON(w, http.StatusOK, svc)
+			return
+		}
+	}
+	writeNotFound(w, "IP service", id)
+}
+
+// ipServiceResponse converts a service as sent by the client into the shape returned
+// by the API, which reports routes like network routes.
+func ipServiceResponse(svc cloudconnexa.IPService) *cloudconnexa.IPServiceResponse {
+	resp := &cloudconnexa.IPServiceResponse{IPService: svc}
+	resp.IPService.Routes = nil
+	for _, r := range svc.Routes {
+		typ, _ := routeType(r.Value)
+		resp.Routes = append(resp.Routes, &cloudconnexa.Route{
+			Type:          typ,
+			Subnet:        canonicalSubnet(r.Value),
+			Description:   r.Description,
+			NetworkItemId: svc.NetworkItemId,
+		})
+	}
+	if resp.Config == nil {
+		resp.Config = &cloudconnexa.IPServiceConfig{}
+	}
+	return resp
+}
+
+func (s *Server) createIPService(w http.ResponseWriter, r *http.Request, _ []string) {
+	itemId := r.URL.Query().Get("networkItemId")
+	itemType := r.URL.Query().Get("networkItemType")
+	if !s.networkItemExists(itemType, itemId) {
+		writeNotFound(w, strings.ToLower(itemType), itemId)
+		return
+	}
+	var svc cloudconnexa.IPService
+	if !readJSON(w, r, &svc) {
+		return
+	}
+	if svc.Name == "" || len(svc.Routes) == 0 {
+		writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", "name and at least one route are required")
+		return
+	}
+	svc.Id = s.newId()
+	svc.NetworkItemId = itemId
+	svc.NetworkItemType = itemType
+	resp := ipServiceResponse(svc)
+	s.ipServices = append(s.ipServices, resp)
+	writeJSON(w, http.StatusCreated, resp)
+}
+
+func (s *Server) updateIPService(w http.ResponseWriter, r *http.Request, params []string) {
+	for i, existing := range s.ipServices {
+		if existing.Id == params[0] {
+			var svc cloudconnexa.IPService
+			if !readJSON(w, r, &svc) {
+				return
+			}
+			svc.Id = existing.Id
+			svc.NetworkItemId = existing.NetworkItemId
+			svc.NetworkItemType = existing.NetworkItemType
+			s.ipServices[i] = ipServiceResponse(svc)
+			writeJSON(w, http.StatusOK, s.ipServices[i])
+			return
+		}
+	}
+	writeNotFound(w, "IP service", params[0])
+}
+
+func (s *Server) deleteIPService(w http.ResponseWriter, _ *http.Request, params []string) {
+	for i, svc := range s.ipServices {
+		if svc.Id == params[0] {
+			s.ipServices = append(s.ipServices[:i], s.ipServices[i+1:]...)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+	writeNotFound(w, "IP service", params[0])
+}
+
+func (s *Server) listRegions(w http.ResponseWriter, _ *http.Request, _ []string) {
+	writeJSON(w, http.StatusOK, Regions)
+}
+
+type page[T any] struct {
+	Content          []T  `json:"content"`
+	NumberOfElements int  `json:"numberOfElements"`
+	Page             int  `json:"page"`
+	Size             int  `json:"size"`
+	Success          bool `json:"success"`
+	TotalElements    int  `json:"totalElements"`
+	TotalPages       int  `json:"totalPages"`
+}
+
+// writePage writes the zero-based page of items selected by the page and size query
+// parameters, like the paginated endpoints of the API.
+func writePage[T any](w http.ResponseWriter, r *http.Request, items []T) {
+	pageNumber, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	size, err := strconv.Atoi(r.URL.Query().Get("size"))
+	if err != nil || size <= 0 {
+		size = 10
+	}
+	p := page[T]{
+		Content:       []T{},
+		Page:          pageNumber,
+		Size:          size,
+		Success:       true,
+		TotalElements: len(items),
+		TotalPages:    (len(items) + size - 1) / size,
+	}
+	if start := pageNumber * size; pageNumber >= 0 && start < len(items) {
+		end := start + size
+		if end > len(items) {
+			end = len(items)
+		}
+		p.Content = items[start:end]
+	}
+	p.NumberOfElements = len(p.Content)
+	writeJSON(w, http.StatusOK, p)
+}
+
+func readJSON(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", fmt.Sprintf("invalid request body: %v", err))
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	writeJSON(w, status, map[string]interface{}{
+		"status":       status,
+		"errorCode":    code,
+		"errorMessage": message,
+	})
+}
+
+// writeFieldError writes a validation error about a single field of the request.
+func writeFieldError(w http.ResponseWriter, status int, code, field, message string) {
+	writeJSON(w, status, map[string]interface{}{
+		"status":       status,
+		"errorCode":    code,
+		"errorMessage": message,
+		"fieldErrors": []map[string]string{
+			{"field": field, "message": message},
+		},
+	})
+}
+
+func writeNotFound(w http.ResponseWriter, kind, id string) {
+	writeError(w, http.StatusNotFound, "NOT_FOUND", fmt.Sprintf("%s %s does not exist", kind, id))
+}
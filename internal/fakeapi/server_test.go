@@ -0,0 +1,167 @@
+package fakeapi
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/openvpn/cloudconnexa-go-client/v2/cloudconnexa"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+)
+
+func newTestClient(t *testing.T) *cloudconnexa.Client {
+	server := NewServer()
+	t.Cleanup(server.Close)
+	c, err := cloudconnexa.NewClient(server.URL, server.ClientID, server.ClientSecret)
+	require.NoError(t, err)
+	c.RateLimiter = rate.NewLimiter(rate.Inf, 0)
+	return c
+}
+
+func TestServer_requiresAuthentication(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodPost, server.URL+"/api/beta/oauth/token", nil)
+	require.NoError(t, err)
+	req.SetBasicAuth(server.ClientID, "wrong")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	resp, err = http.Get(server.URL + "/api/beta/networks/page")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestServer_networkLifecycle(t *testing.T) {
+	c := newTestClient(t)
+
+	network, err := c.Networks.Create(cloudconnexa.Network{
+		Name:           "network",
+		InternetAccess: "LOCAL",
+		Connectors:     []cloudconnexa.NetworkConnector{{Name: "connector", VpnRegionId: "fi-hel"}},
+	})
+	require.NoError(t, err)
+	require.Len(t, network.Connectors, 1)
+	assert.NotEmpty(t, network.Connectors[0].IPv4Address)
+	assert.Len(t, network.SystemSubnets, 2)
+
+	route, err := c.Routes.Create(network.Id, cloudconnexa.Route{Subnet: "10.1.2.0/24", Description: "route"})
+	require.NoError(t, err)
+	assert.Equal(t, "IP_V4", route.Type)
+
+	found, err := c.Routes.Get(route.Id)
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, network.Id, found.NetworkItemId)
+
+	connector, err := c.Connectors.GetByID(network.Connectors[0].Id)
+	require.NoError(t, err)
+	require.NotNil(t, connector)
+	profile, err := c.Connectors.GetProfile(connector.Id)
+	require.NoError(t, err)
+	assert.Contains(t, profile, "fi-hel")
+
+	network.Name = "renamed"
+	require.NoError(t, c.Networks.Update(*network))
+	updated, err := c.Networks.Get(network.Id)
+	require.NoError(t, err)
+	assert.Equal(t, "renamed", updated.Name)
+
+	require.NoError(t, c.Networks.Delete(network.Id))
+	deleted, err := c.Networks.Get(network.Id)
+	require.NoError(t, err)
+	assert.Nil(t, deleted)
+	connector, err = c.Connectors.GetByID(network.Connectors[0].Id)
+	require.NoError(t, err)
+	assert.Nil(t, connector, "connectors are deleted with their network")
+}
+
+func TestServer_rejectsUnknownRegion(t *testing.T) {
+	c := newTestClient(t)
+
+	_, err := c.Hosts.Create(cloudconnexa.Host{
+		Name:       "host",
+		Connectors: []cloudconnexa.Connector{{Name: "connector", VpnRegionId: "mars-1"}},
+	})
+	assert.ErrorContains(t, err, "status code: 400")
+}
+
+func TestServer_paginatesFromPageZero(t *testing.T) {
+	c := newTestClient(t)
+
+	for i := 0; i < 25; i++ {
+		_, err := c.DnsRecords.Create(cloudconnexa.DnsRecord{Domain: fmt.Sprintf("host%d.example.com", i)})
+		require.NoError(t, err)
+	}
+
+	first, err := c.DnsRecords.GetByPage(0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 3, first.TotalPages)
+	assert.Equal(t, 25, first.TotalElements)
+	require.Len(t, first.Content, 10)
+	assert.Equal(t, "host0.example.com", first.Content[0].Domain)
+
+	last, err := c.DnsRecords.GetByPage(2, 10)
+	require.NoError(t, err)
+	assert.Len(t, last.Content, 5)
+
+	beyond, err := c.DnsRecords.GetByPage(3, 10)
+	require.NoError(t, err)
+	assert.Empty(t, beyond.Content)
+
+	record, err := c.DnsRecords.GetDnsRecord(first.Content[9].Id)
+	require.NoError(t, err)
+	assert.Equal(t, "host9.example.com", record.Domain)
+}
+
+func TestServer_userGroupsAndUsers(t *testing.T) {
+	c := newTestClient(t)
+
+	group, err := c.UserGroups.GetByName(DefaultUserGroup)
+	require.NoError(t, err)
+
+	user, err := c.Users.Create(cloudconnexa.User{Username: "user", Email: "user@example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, group.ID, user.GroupId)
+	assert.Equal(t, "MEMBER", user.Role)
+
+	_, err = c.Users.Create(cloudconnexa.User{Username: "user", Email: "other@example.com"})
+	assert.ErrorContains(t, err, "status code: 409")
+}
+
+func TestServer_ipServices(t *testing.T) {
+	c := newTestClient(t)
+
+	host, err := c.Hosts.Create(cloudconnexa.Host{
+		Name:       "host",
+		Connectors: []cloudconnexa.Connector{{Name: "connector", VpnRegionId: "us-west-1"}},
+	})
+	require.NoError(t, err)
+
+	service, err := c.IPServices.Create(&cloudconnexa.IPService{
+		Name:            "service",
+		Type:            "SERVICE_DESTINATION",
+		NetworkItemId:   host.Id,
+		NetworkItemType: "HOST",
+		Config:          &cloudconnexa.IPServiceConfig{ServiceTypes: []string{"ANY"}},
+		Routes:          []*cloudconnexa.IPServiceRoute{{Value: "test.example.com"}},
+	})
+	require.NoError(t, err)
+
+	found, err := c.IPServices.Get(service.Id)
+	require.NoError(t, err)
+	require.Len(t, found.Routes, 1)
+	assert.Equal(t, "test.example.com", found.Routes[0].Subnet)
+	assert.Equal(t, "DOMAIN", found.Routes[0].Type)
+
+	region, err := c.VPNRegions.GetVpnRegion("fi-hel")
+	require.NoError(t, err)
+	require.NotNil(t, region)
+	assert.Equal(t, "Finland", region.Country)
+}